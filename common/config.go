@@ -1,21 +1,34 @@
 package common
 
+import "time"
+
 // Config is the struct of application config.
 type Config struct {
-	ListenAddress string
-	MetricsPath   string
-	Namespace     string
-	NginxUrls     []string
-	NginxPlusUrls []string
-}
-
-// NewConfig creates new application config.
-func NewConfig(listenAddress string, metricsPath string, namespace string, nginxUrls []string, nginxPlusUrls []string) *Config {
-	return &Config{
-		ListenAddress: listenAddress,
-		MetricsPath:   metricsPath,
-		Namespace:     namespace,
-		NginxUrls:     nginxUrls,
-		NginxPlusUrls: nginxPlusUrls,
-	}
+	ListenAddress     string
+	MetricsPath       string
+	Namespace         string
+	NginxUrls         []string
+	NginxPlusUrls     []string
+	HealthcheckUrls   []string
+	PrometheusUrls    []string
+	PrometheusPrefix  string
+	JSONUrls          []string
+	JSONMappingFile   string
+	RegexUrls         []string
+	RegexMappingFile  string
+	FileMaxAge        time.Duration
+	ScrapeTimeout     time.Duration
+	ScrapeConcurrency int
+	ScrapeInterval    time.Duration
+	SnapshotMaxAge    time.Duration
+	MetricsInclude    string
+	MetricsExclude    string
+	ExcludedSections  []string
+	PlusResponses     string
+	RelabelConfigFile string
+	Naming            string
+	CounterContinuity bool
+	PlusStrict        bool
+	ConfigFile        string
+	ProbeLocalTargets bool
 }
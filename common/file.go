@@ -0,0 +1,241 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileConfig is the config of targets loaded from the YAML file, example:
+//
+//	global:
+//	  scrape_timeout: 2s
+//	  labels:
+//	    env: prod
+//	targets:
+//	  - url: https://10.0.0.1:8080/api/3/nginx
+//	    type: plus
+//	    alias: web-1
+//	    basic_auth:
+//	      username: exporter
+//	      password_file: /etc/exporter/password
+//	    tls_config:
+//	      ca_file: /etc/exporter/ca.pem
+//
+// Settings of global section are defaults of every target, labels of targets are merged with global labels.
+type FileConfig struct {
+	Global  TargetSettings `yaml:"global"`
+	Targets []TargetConfig `yaml:"targets"`
+}
+
+// TargetSettings are settings of scraping the target which can be set globally.
+type TargetSettings struct {
+	ScrapeTimeout time.Duration     `yaml:"scrape_timeout"`
+	Labels        map[string]string `yaml:"labels"`
+	BasicAuth     *BasicAuth        `yaml:"basic_auth"`
+	BearerToken   string            `yaml:"bearer_token"`
+	TLSConfig     *TLSConfig        `yaml:"tls_config"`
+}
+
+// TargetConfig is the config of the single target, type is the module of stats of the target(e.g. stub, plus).
+type TargetConfig struct {
+	URL            string `yaml:"url"`
+	Type           string `yaml:"type"`
+	Alias          string `yaml:"alias"`
+	TargetSettings `yaml:",inline"`
+}
+
+// BasicAuth is the username and the password sent to the target, the password is read from the file when it's set.
+type BasicAuth struct {
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"password_file"`
+}
+
+// TLSConfig is the config of TLS connections to the target.
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// LoadConfigFile loads the config of targets from YAML file, global settings are applied to targets
+// and targets are validated.
+func LoadConfigFile(filename string) (*FileConfig, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file '%s': %s", filename, err)
+	}
+
+	config := &FileConfig{}
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return nil, fmt.Errorf("error parsing config file '%s': %s", filename, err)
+	}
+
+	if err := config.Global.validate(); err != nil {
+		return nil, fmt.Errorf("invalid global settings of config file '%s': %s", filename, err)
+	}
+
+	for i := range config.Targets {
+		t := &config.Targets[i]
+		t.applyDefaults(config.Global)
+
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("invalid target #%d of config file '%s': %s", i+1, filename, err)
+		}
+	}
+
+	return config, nil
+}
+
+// applyDefaults sets settings missing in the target to global settings, global labels are overridden
+// by labels of the target
+func (t *TargetConfig) applyDefaults(global TargetSettings) {
+	if t.ScrapeTimeout == 0 {
+		t.ScrapeTimeout = global.ScrapeTimeout
+	}
+
+	if t.BasicAuth == nil && t.BearerToken == "" {
+		t.BasicAuth, t.BearerToken = global.BasicAuth, global.BearerToken
+	}
+
+	if t.TLSConfig == nil {
+		t.TLSConfig = global.TLSConfig
+	}
+
+	if len(global.Labels) > 0 {
+		labels := make(map[string]string, len(global.Labels)+len(t.Labels))
+		for name, value := range global.Labels {
+			labels[name] = value
+		}
+		for name, value := range t.Labels {
+			labels[name] = value
+		}
+		t.Labels = labels
+	}
+}
+
+// validate checks that the target has the url and the type and its settings are valid
+func (t *TargetConfig) validate() error {
+	if t.URL == "" {
+		return errors.New("url is missing")
+	}
+
+	if t.Type == "" {
+		return fmt.Errorf("type of target '%s' is missing", t.URL)
+	}
+
+	if err := t.TargetSettings.validate(); err != nil {
+		return fmt.Errorf("target '%s': %s", t.URL, err)
+	}
+
+	return nil
+}
+
+// validate checks that settings are consistent and files of settings are readable
+func (s *TargetSettings) validate() error {
+	if s.ScrapeTimeout < 0 {
+		return fmt.Errorf("negative scrape timeout %s", s.ScrapeTimeout)
+	}
+
+	if s.BasicAuth != nil && s.BearerToken != "" {
+		return errors.New("basic_auth and bearer_token are mutually exclusive")
+	}
+
+	if s.BasicAuth != nil {
+		if s.BasicAuth.Username == "" {
+			return errors.New("username of basic_auth is missing")
+		}
+		if s.BasicAuth.Password != "" && s.BasicAuth.PasswordFile != "" {
+			return errors.New("password and password_file of basic_auth are mutually exclusive")
+		}
+		if _, err := s.BasicAuth.password(); err != nil {
+			return err
+		}
+	}
+
+	if s.TLSConfig != nil {
+		if _, err := s.TLSConfig.Build(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Headers returns headers authorizing requests of stats of the target.
+func (s *TargetSettings) Headers() (map[string]string, error) {
+	if s.BearerToken != "" {
+		return map[string]string{"Authorization": "Bearer " + s.BearerToken}, nil
+	}
+
+	if s.BasicAuth == nil {
+		return nil, nil
+	}
+
+	password, err := s.BasicAuth.password()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte(s.BasicAuth.Username + ":" + password))
+
+	return map[string]string{"Authorization": "Basic " + credentials}, nil
+}
+
+// password returns the password of basic auth, the password file is read on every call
+func (a *BasicAuth) password() (string, error) {
+	if a.PasswordFile == "" {
+		return a.Password, nil
+	}
+
+	content, err := ioutil.ReadFile(a.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading password file '%s': %s", a.PasswordFile, err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// Build creates TLS config of the client loading the CA and the client certificate from files.
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file '%s': %s", c.CAFile, err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file '%s'", c.CAFile)
+		}
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("cert_file and key_file of tls_config have to be set together")
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate '%s': %s", c.CertFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
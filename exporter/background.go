@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultSnapshotIntervals is the number of scrape intervals metrics of failed targets are kept by default
+const defaultSnapshotIntervals = 3
+
+// Start starts scraping targets in background if scrape interval is set, the first scrape is performed
+// before returning, so the snapshot is complete as soon as the exporter is started
+func (exp *nginxPlusExporter) Start() {
+	if exp.options.ScrapeInterval <= 0 || exp.stop != nil {
+		return
+	}
+
+	exp.refresh()
+
+	exp.stop, exp.done = make(chan struct{}), make(chan struct{})
+	go exp.loop(exp.stop, exp.done)
+}
+
+// Stop stops scraping targets in background and waits for the running scrape to complete
+func (exp *nginxPlusExporter) Stop() {
+	if exp.stop != nil {
+		close(exp.stop)
+		<-exp.done
+		exp.stop, exp.done = nil, nil
+	}
+}
+
+// loop scrapes targets every scrape interval until stop is closed, done is closed on return
+func (exp *nginxPlusExporter) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(exp.options.ScrapeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		exp.refresh()
+	}
+}
+
+// refresh scrapes targets and replaces the snapshot
+func (exp *nginxPlusExporter) refresh() {
+	results := exp.scrape()
+
+	exp.Lock()
+	exp.keepSnapshot(results)
+	exp.snapshot = results
+	exp.Unlock()
+}
+
+// keepSnapshot keeps metrics of the current snapshot for targets whose scrape failed, so failed scrapes don't
+// drop series of targets and the timestamp of the result is the time of the last successful scrape of the target.
+// Metrics older than the maximum age of snapshot are dropped, such targets and targets which were never scraped
+// successfully have zero timestamp
+func (exp *nginxPlusExporter) keepSnapshot(results []scrapeResult) {
+	maxAge := exp.options.SnapshotMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultSnapshotIntervals * exp.options.ScrapeInterval
+	}
+
+	for i := range results {
+		if results[i].err == nil {
+			continue
+		}
+
+		scraped := results[i].timestamp
+		results[i].metrics, results[i].timestamp = nil, time.Time{}
+		if exp.snapshot != nil && scraped.Sub(exp.snapshot[i].timestamp) <= maxAge {
+			results[i].metrics, results[i].timestamp = exp.snapshot[i].metrics, exp.snapshot[i].timestamp
+		}
+	}
+}
+
+// exposeSnapshotAge exposes age of the snapshot of every target which was scraped successfully
+func (exp *nginxPlusExporter) exposeSnapshotAge(ch chan<- prometheus.Metric) {
+	now := time.Now()
+
+	for i, result := range exp.snapshot {
+		if result.timestamp.IsZero() {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			exp.snapshotAge,
+			prometheus.GaugeValue,
+			now.Sub(result.timestamp).Seconds(),
+			exp.targets[i].labelValues(exp.labelNames)...,
+		)
+	}
+}
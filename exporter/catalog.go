@@ -0,0 +1,187 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// family is the metric family with the descriptor of its metrics
+type family struct {
+	metric.Family
+
+	fqName      string
+	labelNames  []string
+	sortedNames []string
+	valueType   prometheus.ValueType
+	desc        *prometheus.Desc
+	exposed     bool
+}
+
+// newFamily creates metric family with the descriptor built once for all its metrics
+func newFamily(namespace string, f metric.Family, labelNames []string, exposed bool) *family {
+	valueType := prometheus.GaugeValue
+	if f.Type == metric.Counter {
+		valueType = prometheus.CounterValue
+	}
+
+	sortedNames := append([]string(nil), labelNames...)
+	sort.Strings(sortedNames)
+
+	fqName := prometheus.BuildFQName(namespace, "", f.Name)
+
+	return &family{
+		Family:      f,
+		fqName:      fqName,
+		labelNames:  labelNames,
+		sortedNames: sortedNames,
+		valueType:   valueType,
+		desc:        prometheus.NewDesc(fqName, f.Help, labelNames, nil),
+		exposed:     exposed,
+	}
+}
+
+// hasLabels checks whether labels are exactly labels of the family
+func (f *family) hasLabels(labels map[string]string) bool {
+	if len(labels) != len(f.sortedNames) {
+		return false
+	}
+
+	for _, name := range f.sortedNames {
+		if _, ok := labels[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newSample creates sample of the family from the metric, label pairs are sorted by name as required by registry
+func (f *family) newSample(m metric.Metric, pairs labelPairs) (*sample, error) {
+	if len(m.Labels) != len(f.sortedNames) {
+		return nil, fmt.Errorf("inconsistent label cardinality: expected %d, got %d", len(f.sortedNames), len(m.Labels))
+	}
+
+	labels := make([]*dto.LabelPair, len(f.sortedNames))
+	for i, name := range f.sortedNames {
+		value, ok := m.Labels[name]
+		if !ok {
+			return nil, fmt.Errorf("missing label '%s'", name)
+		}
+		labels[i] = pairs.get(name, value)
+	}
+
+	return &sample{family: f, value: m.Value, labels: labels}, nil
+}
+
+// catalog is the set of metric families declared by scrapers of targets,
+// families of metrics missing in the declared catalog are created on first scrape
+type catalog struct {
+	namespace string
+	exposes   func(name string) bool
+	relabeled bool
+	declared  map[string]map[string]*family
+	descs     []*prometheus.Desc
+	dynamic   map[string]*family
+
+	sync.Mutex
+}
+
+// newCatalog creates catalog of metric families declared by scrapers of the targets with the passed labels of targets,
+// only families of names accepted by filters of options are exposed
+func newCatalog(namespace string, targets []*target, labelNames []string, options Options) *catalog {
+	c := &catalog{
+		namespace: namespace,
+		exposes:   options.exposes,
+		relabeled: len(options.RelabelConfigs) > 0,
+		declared:  map[string]map[string]*family{},
+		dynamic:   map[string]*family{},
+	}
+
+	for _, t := range targets {
+		describer, ok := t.scraper.(scraper.Describer)
+		if !ok {
+			continue
+		}
+
+		namespace := c.targetNamespace(t)
+		if c.declared[namespace] == nil {
+			c.declared[namespace] = map[string]*family{}
+		}
+
+		for _, f := range describer.Families() {
+			if _, ok := c.declared[namespace][f.Name]; ok {
+				continue
+			}
+
+			declared := newFamily(namespace, f, f.LabelNames(labelNames), c.exposes(f.Name))
+			c.declared[namespace][f.Name] = declared
+			if declared.exposed {
+				c.descs = append(c.descs, declared.desc)
+			}
+		}
+	}
+
+	return c
+}
+
+// describe describes metric families declared by scrapers, labels of families are unknown in advance
+// when metrics are relabeled, so nothing is described in this case
+func (c *catalog) describe(ch chan<- *prometheus.Desc) {
+	if c.relabeled {
+		return
+	}
+
+	for _, desc := range c.descs {
+		ch <- desc
+	}
+}
+
+// targetNamespace returns the namespace of metrics of the target, scrapers may expose metrics in their own namespace
+func (c *catalog) targetNamespace(t *target) string {
+	if namespacer, ok := t.scraper.(scraper.Namespacer); ok {
+		return namespacer.Namespace()
+	}
+
+	return c.namespace
+}
+
+// family returns the family of the metric in the namespace, the family of undeclared metric is created by its name,
+// type and labels, the declared family with labels changed by relabeling keeps its type and help
+func (c *catalog) family(namespace string, m metric.Metric) *family {
+	declared, ok := c.declared[namespace][m.Name]
+	if ok && declared.hasLabels(m.Labels) {
+		return declared
+	}
+
+	labelNames := make([]string, 0, len(m.Labels))
+	for name := range m.Labels {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames)
+
+	key := namespace + "\xff" + m.Name + "\xff" + strings.Join(labelNames, "\xff")
+
+	c.Lock()
+	defer c.Unlock()
+
+	f, ok := c.dynamic[key]
+	if !ok {
+		dynamic := metric.Family{Name: m.Name, Type: m.Type}
+		if declared != nil {
+			dynamic.Type = declared.Type
+			dynamic.Help = declared.Help
+		}
+
+		f = newFamily(namespace, dynamic, labelNames, c.exposes(m.Name))
+		c.dynamic[key] = f
+	}
+
+	return f
+}
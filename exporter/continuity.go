@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+)
+
+// continuity keeps counters of the target monotonic across resets, counters are offset by their last values
+// seen before the reset, resets of all counters are detected by the scraper of the target and the single counter
+// is treated as reset when its value decreases
+type continuity struct {
+	detector  scraper.ResetDetector
+	detection []float64
+	last      map[string]float64
+	offsets   map[string]float64
+}
+
+// newContinuity creates continuity of counters of the target
+func newContinuity(t *target) *continuity {
+	c := &continuity{
+		last:    map[string]float64{},
+		offsets: map[string]float64{},
+	}
+
+	if detector, ok := t.scraper.(scraper.ResetDetector); ok && len(detector.ResetMetrics()) > 0 {
+		c.detector = detector
+	}
+
+	return c
+}
+
+// inherit copies last values, offsets and values of reset metrics of the previous continuity of the target
+func (c *continuity) inherit(previous *continuity) {
+	if previous.detection != nil {
+		c.detection = append([]float64(nil), previous.detection...)
+	}
+
+	for key, value := range previous.last {
+		c.last[key] = value
+	}
+	for key, value := range previous.offsets {
+		c.offsets[key] = value
+	}
+}
+
+// apply offsets values of counters of the scraped metrics and reports whether the reset of the target is detected,
+// series missing in the complete scrape are forgotten
+func (c *continuity) apply(metrics []metric.Metric, isCounter func(m metric.Metric) bool, complete bool) bool {
+	reset := c.detect(metrics)
+
+	last, offsets := c.last, c.offsets
+	if complete {
+		last, offsets = make(map[string]float64, len(c.last)), make(map[string]float64, len(c.offsets))
+	}
+
+	for i := range metrics {
+		m := &metrics[i]
+		if !isCounter(*m) {
+			continue
+		}
+
+		key := seriesKey(*m)
+		offset := c.offsets[key]
+		if previous, ok := c.last[key]; ok && (reset || m.Value < previous) {
+			offset += previous
+		}
+
+		last[key] = m.Value
+		if offset > 0 {
+			offsets[key] = offset
+		}
+		m.Value += offset
+	}
+
+	c.last, c.offsets = last, offsets
+
+	return reset
+}
+
+// detect detects the reset of the target by values of reset metrics of the scraper compared to the previous scrape
+func (c *continuity) detect(metrics []metric.Metric) bool {
+	if c.detector == nil {
+		return false
+	}
+
+	names := c.detector.ResetMetrics()
+	current := make([]float64, len(names))
+	found := make([]bool, len(names))
+
+	for _, m := range metrics {
+		for i, name := range names {
+			if !found[i] && m.Name == name {
+				current[i], found[i] = m.Value, true
+			}
+		}
+	}
+
+	for _, ok := range found {
+		if !ok {
+			return false
+		}
+	}
+
+	reset := c.detection != nil && c.detector.Reset(c.detection, current)
+	c.detection = current
+
+	return reset
+}
+
+// seriesKey returns the key identifying series of the metric by its name and labels
+func seriesKey(m metric.Metric) string {
+	names := make([]string, 0, len(m.Labels))
+	for name := range m.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	key := make([]string, 0, 2*len(names)+1)
+	key = append(key, m.Name)
+	for _, name := range names {
+		key = append(key, name, m.Labels[name])
+	}
+
+	return strings.Join(key, "\xff")
+}
@@ -1,53 +1,209 @@
 package exporter
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
-	"net/url"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/monitoring-tools/prom-nginx-exporter/common"
 	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/relabel"
 	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 )
 
 const (
-	// nginxModule is used to define nginx urls with standard module(ngx_http_stub_status_module)
-	nginxModule = "nginx"
-	// nginxPlusModule is used to define nginx urls with Plus module(ngx_http_status_module)
-	nginxPlusModule = "nginxPlus"
+	// NginxModule is used to define nginx urls with standard module(ngx_http_stub_status_module)
+	NginxModule = "nginx"
+	// NginxPlusModule is used to define nginx urls with Plus module(ngx_http_status_module)
+	NginxPlusModule = "nginxPlus"
+	// HealthcheckModule is used to define urls with status page of lua-resty-upstream-healthcheck module
+	HealthcheckModule = "healthcheck"
+	// PrometheusModule is used to define urls exposing metrics in Prometheus text format(e.g. nginx-lua-prometheus)
+	PrometheusModule = "prometheus"
+	// JSONModule is used to define urls with arbitrary JSON stats scraped using the mapping
+	JSONModule = "json"
+	// RegexModule is used to define urls with line-oriented text stats scraped using regular expressions
+	RegexModule = "regex"
 )
 
+const (
+	// DefaultNaming is the naming scheme of metrics of the exporter
+	DefaultNaming = "default"
+	// OfficialNaming is the naming scheme of metrics of nginx-prometheus-exporter for nginx and nginx plus modules
+	OfficialNaming = "official"
+)
+
+// Namings are supported naming schemes of metrics
+var Namings = []string{DefaultNaming, OfficialNaming}
+
+// Module is the set of stats urls of nginx module and the scraper of their stats
+type Module struct {
+	Name    string
+	Scraper scraper.Scraper
+	Urls    []string
+	// Targets are stats urls of the module with their own settings
+	Targets []Target
+}
+
+// Target is the stats url with settings of scraping it
+type Target struct {
+	// URL is the stats url of the target in the same format as urls of the module
+	URL string
+	// Alias replaces the value of "server" label, empty means the host of url
+	Alias string
+	// Labels are static labels added to every metric of the target
+	Labels map[string]string
+	// Headers are added to every request of stats(e.g. Authorization)
+	Headers map[string]string
+	// Client requests stats of the target, nil means the client of the exporter
+	Client *http.Client
+	// ScrapeTimeout is the deadline of scraping the target, 0 means the scrape timeout of options
+	ScrapeTimeout time.Duration
+}
+
+// Options is the set of options of scraping targets
+type Options struct {
+	// FileMaxAge is maximum age of stats file after which the target is treated as down, 0 disables the check
+	FileMaxAge time.Duration
+	// Concurrency is maximum number of targets scraped in parallel, 0 means all targets at once
+	Concurrency int
+	// ScrapeTimeout is the deadline of scraping single target, 0 disables the deadline
+	ScrapeTimeout time.Duration
+	// ScrapeInterval is the interval of scraping targets in background, 0 means scraping on every collect
+	ScrapeInterval time.Duration
+	// SnapshotMaxAge is maximum age of metrics of failed targets kept from their last successful scrape in background,
+	// 0 means three scrape intervals
+	SnapshotMaxAge time.Duration
+	// MetricsInclude matches names of metric families to expose, nil means all families
+	MetricsInclude *regexp.Regexp
+	// MetricsExclude matches names of metric families which are not exposed, nil means no families
+	MetricsExclude *regexp.Regexp
+	// RelabelConfigs are relabel rules applied to every scraped metric
+	RelabelConfigs []*relabel.Config
+	// Naming is the naming scheme of metrics, empty means the default scheme
+	Naming string
+	// CounterContinuity keeps counters monotonic across detected resets of targets by offsetting them
+	CounterContinuity bool
+	// ProbeLocalTargets allows probes of stats files and unix sockets, otherwise only http and https urls are probed
+	ProbeLocalTargets bool
+}
+
+// exposes checks whether the metric family of the name is exposed according to include and exclude filters
+func (o Options) exposes(name string) bool {
+	if o.MetricsInclude != nil && !o.MetricsInclude.MatchString(name) {
+		return false
+	}
+
+	return o.MetricsExclude == nil || !o.MetricsExclude.MatchString(name)
+}
+
+// scrapeResult is the result of the completed scrape of the target
+type scrapeResult struct {
+	metrics     []metric.Metric
+	err         error
+	duration    time.Duration
+	timestamp   time.Time
+	lastSuccess time.Time
+	// unknownFields are numbers of fields of stats unknown to the scraper by section
+	unknownFields map[string]int
+}
+
 // nginxPlusExporter is nginx and nginx plus stats exporter
 type nginxPlusExporter struct {
-	namespace     string
-	nginxUrls     []string
-	nginxPlusUrls []string
-
-	client           *http.Client
-	nginxScraper     scraper.NginxScraper
-	nginxPlusScraper scraper.NginxPlusScraper
+	namespace string
+	targets   []*target
+	options   Options
 
 	duration     prometheus.Summary
 	totalScrapes prometheus.Counter
+	snapshotAge  *prometheus.Desc
+	labelNames   []string
+	status       *targetStatus
+	catalog      *catalog
+	continuity   []*continuity
+
+	lastSuccess []time.Time
+	snapshot    []scrapeResult
+	stop        chan struct{}
+	done        chan struct{}
+
+	reportedFields map[string]bool
+	reportMutex    sync.Mutex
 
 	sync.RWMutex
 }
 
-// NewNginxPlusExporter creates nginx and nginx plus stats exporter
+// NewNginxPlusExporter creates nginx and nginx plus stats exporter, invalid urls of targets are fatal
 func NewNginxPlusExporter(
 	client *http.Client,
-	nginxScraper scraper.NginxScraper,
-	nginxPlusScraper scraper.NginxPlusScraper,
 	namespace string,
-	nginxUrls []string,
-	nginxPlusUrls []string,
+	modules []Module,
+	options Options,
 ) *nginxPlusExporter {
+	exp, err := NewExporter(client, namespace, modules, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return exp
+}
+
+// NewExporter creates nginx and nginx plus stats exporter, invalid urls and settings of targets are returned as error
+func NewExporter(
+	client *http.Client,
+	namespace string,
+	modules []Module,
+	options Options,
+) (*nginxPlusExporter, error) {
+	targets := []*target{}
+	for _, module := range modules {
+		moduleTargets, err := newTargets(module, client)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, moduleTargets...)
+	}
+
+	exp := newExporter(namespace, targets, options)
+	if err := exp.checkIdentities(); err != nil {
+		return nil, err
+	}
 
+	return exp, nil
+}
+
+// checkIdentities checks that every target has distinct values of labels, otherwise metrics of targets
+// (e.g. two stats urls on the same host and port) are duplicated
+func (exp *nginxPlusExporter) checkIdentities() error {
+	identities := make(map[string]*target, len(exp.targets))
+	for _, t := range exp.targets {
+		values := t.labelValues(exp.labelNames)
+		identity := strings.Join(values, "\xff")
+		if other, ok := identities[identity]; ok {
+			labels := make([]string, len(values))
+			for i, value := range values {
+				labels[i] = fmt.Sprintf("%s=%q", exp.labelNames[i], value)
+			}
+			return fmt.Errorf(
+				"targets '%s' and '%s' have the same labels {%s}, set distinct aliases of targets",
+				other, t, strings.Join(labels, ", "),
+			)
+		}
+		identities[identity] = t
+	}
+
+	return nil
+}
+
+// newExporter creates exporter of the targets
+func newExporter(namespace string, targets []*target, options Options) *nginxPlusExporter {
 	duration := prometheus.NewSummary(prometheus.SummaryOpts{
 		Namespace: namespace,
 		Name:      "last_scrape_duration_seconds",
@@ -60,15 +216,86 @@ func NewNginxPlusExporter(
 		Help:      "Current total nginx scrapes.",
 	})
 
-	return &nginxPlusExporter{
-		client:           client,
-		namespace:        namespace,
-		nginxUrls:        nginxUrls,
-		nginxPlusUrls:    nginxPlusUrls,
-		nginxScraper:     nginxScraper,
-		nginxPlusScraper: nginxPlusScraper,
-		duration:         duration,
-		totalScrapes:     totalScrapes,
+	identity := targetLabelNames
+	if options.Naming == OfficialNaming {
+		identity = officialTargetLabelNames
+		for _, t := range targets {
+			t.useOfficialNaming()
+		}
+	}
+
+	labelNames := targetsLabelNames(targets, identity)
+
+	snapshotAge := prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "target_snapshot_age_seconds"),
+		"Seconds since the last successful background scrape of the target whose metrics are exposed.",
+		labelNames,
+		nil,
+	)
+
+	exp := &nginxPlusExporter{
+		namespace:    namespace,
+		targets:      targets,
+		options:      options,
+		duration:     duration,
+		totalScrapes: totalScrapes,
+		snapshotAge:  snapshotAge,
+		labelNames:   labelNames,
+		status:       newTargetStatus(namespace, targets, labelNames, options.CounterContinuity),
+		lastSuccess:  make([]time.Time, len(targets)),
+		catalog:      newCatalog(namespace, targets, labelNames, options),
+
+		reportedFields: map[string]bool{},
+	}
+
+	if options.CounterContinuity {
+		exp.continuity = make([]*continuity, len(targets))
+		for i, t := range targets {
+			exp.continuity[i] = newContinuity(t)
+		}
+	}
+
+	return exp
+}
+
+// Inherit takes over the state of targets of the previous exporter which are kept by the exporter: continuity
+// of counters, counters of errors and resets, the time of the last successful scrape and the background snapshot,
+// so the state survives reloads of configuration. The previous exporter has to be stopped before.
+func (exp *nginxPlusExporter) Inherit(previous prometheus.Collector) {
+	prev, ok := previous.(*nginxPlusExporter)
+	if !ok {
+		return
+	}
+
+	prev.Lock()
+	defer prev.Unlock()
+	exp.Lock()
+	defer exp.Unlock()
+
+	indexes := make(map[string]int, len(prev.targets))
+	for i, t := range prev.targets {
+		indexes[t.key()] = i
+	}
+
+	for i, t := range exp.targets {
+		j, ok := indexes[t.key()]
+		if !ok {
+			continue
+		}
+
+		exp.lastSuccess[i] = prev.lastSuccess[j]
+		exp.status.inherit(prev.status, t, prev.targets[j])
+
+		if exp.continuity != nil && prev.continuity != nil {
+			exp.continuity[i].inherit(prev.continuity[j])
+		}
+
+		if exp.options.ScrapeInterval > 0 && prev.snapshot != nil {
+			if exp.snapshot == nil {
+				exp.snapshot = make([]scrapeResult, len(exp.targets))
+			}
+			exp.snapshot[i] = prev.snapshot[j]
+		}
 	}
 }
 
@@ -76,125 +303,301 @@ func NewNginxPlusExporter(
 func (exp *nginxPlusExporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- exp.duration.Desc()
 	ch <- exp.totalScrapes.Desc()
+	exp.status.describe(ch)
+	exp.catalog.describe(ch)
+
+	if exp.options.ScrapeInterval > 0 {
+		ch <- exp.snapshotAge
+	}
 }
 
-// Collect collects nginx and nginx plus metrics
+// Collect collects nginx and nginx plus metrics, in background mode the last completed snapshot is exposed
 func (exp *nginxPlusExporter) Collect(ch chan<- prometheus.Metric) {
+	if exp.options.ScrapeInterval > 0 {
+		exp.RLock()
+		defer exp.RUnlock()
+
+		exp.expose(ch, exp.snapshot)
+		exp.status.expose(ch, exp.targets, exp.snapshot)
+		exp.exposeSnapshotAge(ch)
+		return
+	}
+
 	exp.Lock()
 	defer exp.Unlock()
 
-	exp.expose(ch, exp.collect(exp.scrape()))
+	results := exp.scrape()
+	exp.expose(ch, results)
+	exp.status.expose(ch, exp.targets, results)
 }
 
 // scrape scrapes nginx or nginx plus stats for the passed urls
-func (exp *nginxPlusExporter) scrape() chan metric.Metric {
-	metrics := make(chan metric.Metric)
+func (exp *nginxPlusExporter) scrape() []scrapeResult {
+	now := time.Now().UnixNano()
+	exp.totalScrapes.Inc()
 
-	go func() {
-		now := time.Now().UnixNano()
-		exp.totalScrapes.Inc()
+	results := exp.scrapeTargets()
 
-		exp.scrapeModule(nginxModule, exp.nginxUrls, metrics)
-		exp.scrapeModule(nginxPlusModule, exp.nginxPlusUrls, metrics)
+	exp.duration.Observe(float64(time.Now().UnixNano()-now) / 1000000000)
 
-		exp.duration.Observe(float64(time.Now().UnixNano()-now) / 1000000000)
+	return results
+}
 
-		close(metrics)
-	}()
+// scrapeTargets scrapes targets in parallel limited by concurrency option,
+// results are returned in order of targets regardless of the order of scrape completion
+func (exp *nginxPlusExporter) scrapeTargets() []scrapeResult {
+	var (
+		results     = make([]scrapeResult, len(exp.targets))
+		concurrency = exp.options.Concurrency
+		wg          sync.WaitGroup
+	)
+
+	if concurrency <= 0 || concurrency > len(exp.targets) {
+		concurrency = len(exp.targets)
+	}
 
-	return metrics
-}
+	workers := make(chan struct{}, concurrency)
+	for i, t := range exp.targets {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func(i int, t *target) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			start := time.Now()
+			metrics, err := exp.scrapeTargetMetrics(t)
+			complete := err == nil
+			var unknownFields map[string]int
+			if partial, ok := err.(*scraper.PartialError); ok {
+				if len(partial.Sections) > 0 {
+					log.Warnf("error scraping %s stats using address '%s': %s", t.module, t, partial)
+				}
+				exp.status.observeParseErrors(t, partial.Sections)
+				exp.reportUnknownFields(t, partial.UnknownFields)
+				unknownFields = fieldSections(partial.UnknownFields)
+				complete, err = len(partial.Sections) == 0, nil
+			}
+			if err != nil {
+				log.Error(err)
+			}
+			exp.status.observe(t, err)
+
+			if exp.continuity != nil && exp.continuity[i].apply(metrics, exp.isCounter(t), complete) {
+				log.Infof("counters of '%s' were reset", t)
+				exp.status.observeReset(t)
+			}
+
+			now := time.Now()
+			results[i] = scrapeResult{metrics: metrics, err: err, duration: now.Sub(start), timestamp: now, unknownFields: unknownFields}
+		}(i, t)
+	}
 
-// expose returns metrics to base metric channel
-func (exp *nginxPlusExporter) expose(ch chan<- prometheus.Metric, metrics map[string]*prometheus.GaugeVec) {
+	wg.Wait()
 
-	ch <- exp.duration
-	ch <- exp.totalScrapes
+	for i := range results {
+		if results[i].err == nil {
+			exp.lastSuccess[i] = results[i].timestamp
+		}
+		results[i].lastSuccess = exp.lastSuccess[i]
+	}
 
-	for _, m := range metrics {
-		m.Collect(ch)
+	return results
+}
+
+// reportUnknownFields logs JSON paths of fields of stats of the target which are not exported yet,
+// every path of the module is logged once
+func (exp *nginxPlusExporter) reportUnknownFields(t *target, fields []string) {
+	exp.reportMutex.Lock()
+	defer exp.reportMutex.Unlock()
+
+	unreported := []string{}
+	for _, field := range fields {
+		key := t.module + " " + field
+		if !exp.reportedFields[key] {
+			exp.reportedFields[key] = true
+			unreported = append(unreported, field)
+		}
+	}
+
+	if len(unreported) > 0 {
+		log.Warnf("fields of %s stats using address '%s' are not exported yet: %s", t.module, t, strings.Join(unreported, ", "))
 	}
 }
 
-// collect collects all metrics to map
-func (exp *nginxPlusExporter) collect(metrics <-chan metric.Metric) map[string]*prometheus.GaugeVec {
-	m := map[string]*prometheus.GaugeVec{}
+// fieldSections counts JSON paths of fields by sections of stats, the section is the first element of the path
+func fieldSections(fields []string) map[string]int {
+	if len(fields) == 0 {
+		return nil
+	}
 
-	for item := range metrics {
-		metricKey := exp.namespace + "_" + item.Name
+	sections := map[string]int{}
+	for _, field := range fields {
+		sections[strings.SplitN(field, ".", 2)[0]]++
+	}
 
-		gaugeOpt := prometheus.GaugeOpts{
-			Namespace: exp.namespace,
-			Name:      item.Name,
-		}
+	return sections
+}
 
-		labelNames := make([]string, 0, len(item.Labels))
-		for labelName := range item.Labels {
-			labelNames = append(labelNames, labelName)
-		}
+// scrapeTargetMetrics scrapes the target within the scrape timeout of the target or options, metrics scraped
+// before error are returned too, the panic of the scraper fails only the scrape of the target. Metrics are still
+// passed through the channel of the Scraper interface and collected into the slice of the target. Metrics with
+// invalid names produced by relabeling are dropped and counted as parse errors of the section "relabel".
+func (exp *nginxPlusExporter) scrapeTargetMetrics(t *target) ([]metric.Metric, error) {
+	timeout := exp.options.ScrapeTimeout
+	if t.timeout > 0 {
+		timeout = t.timeout
+	}
 
-		m[metricKey] = prometheus.NewGaugeVec(gaugeOpt, labelNames)
+	ctx, cancel := context.Background(), func() {}
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	var (
+		metrics = make(chan metric.Metric, 100)
+		errs    = make(chan error, 1)
+		result  []metric.Metric
+	)
+
+	go func() {
+		defer close(metrics)
+		defer func() {
+			if r := recover(); r != nil {
+				errs <- newScrapeError(reasonParse, "panic while scraping '%s': %v", t, r)
+			}
+		}()
+
+		errs <- exp.scrapeTarget(ctx, t, metrics)
+	}()
 
-		if val, err := common.ConvertValueToFloat64(item.Value); err != nil {
-			log.Errorf("convert error for metric '%s': %s", item.Name, err)
+	var invalid []string
+	for m := range metrics {
+		m, ok, err := relabel.Process(m, exp.options.RelabelConfigs)
+		if err != nil {
+			if len(invalid) == 0 {
+				log.Warnf("metrics of '%s' are dropped by relabeling: %s", t, err)
+			}
+			invalid = append(invalid, relabelSection)
 			continue
-		} else {
-			m[metricKey].With(item.Labels).Set(val)
+		}
+		if ok {
+			result = append(result, m)
 		}
 	}
+	exp.status.observeParseErrors(t, invalid)
 
-	return m
+	return result, <-errs
 }
 
-// scrapeModule scrapes stats for module(nginx or nginx plus)
-func (exp *nginxPlusExporter) scrapeModule(module string, urls []string, metrics chan<- metric.Metric) {
-	for _, u := range urls {
-		addr, err := url.Parse(u)
-		if err != nil {
-			log.Fatalf("unable to parse address '%s': %s", u, err)
-		}
+// isCounter returns the function checking whether the metric of the target is a counter according to the catalog
+func (exp *nginxPlusExporter) isCounter(t *target) func(m metric.Metric) bool {
+	namespace := exp.catalog.targetNamespace(t)
 
-		labels := map[string]string{
-			"port":   addr.Port(),
-			"server": addr.Hostname(),
-		}
+	return func(m metric.Metric) bool {
+		return exp.catalog.family(namespace, m).valueType == prometheus.CounterValue
+	}
+}
 
-		err = exp.scrapeURL(module, addr, metrics, labels)
-		if err != nil {
-			log.Error(err)
+// expose returns metrics to base metric channel
+func (exp *nginxPlusExporter) expose(ch chan<- prometheus.Metric, results []scrapeResult) {
+	ch <- exp.duration
+	ch <- exp.totalScrapes
+
+	exp.collect(ch, results)
+}
+
+// collect converts scraped metrics of targets to samples of metric families, metrics of the same
+// name and labels scraped more than once are exposed only the first time, NaN and Inf are valid values
+// of gauges(e.g. quantiles of empty summaries passed through) and are dropped only for counters
+func (exp *nginxPlusExporter) collect(ch chan<- prometheus.Metric, results []scrapeResult) {
+	var (
+		pairs = labelPairs{}
+		seen  = map[uint64]struct{}{}
+	)
+
+	for i, result := range results {
+		namespace := exp.catalog.targetNamespace(exp.targets[i])
+
+		for _, item := range result.metrics {
+			f := exp.catalog.family(namespace, item)
+			if !f.exposed {
+				continue
+			}
+
+			if f.valueType == prometheus.CounterValue && (math.IsNaN(item.Value) || math.IsInf(item.Value, 0) || item.Value < 0) {
+				log.Errorf("convert error for metric '%s': counter value %v is NaN, Inf or negative", item.Name, item.Value)
+				continue
+			}
+
+			s, err := f.newSample(item, pairs)
+			if err != nil {
+				log.Errorf("labels error for metric '%s': %s", item.Name, err)
+				continue
+			}
+
+			hash := s.hash(f.fqName)
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+
+			ch <- s
 		}
 	}
 }
 
-// scrapeURL scrapes stats for passed url
-func (exp *nginxPlusExporter) scrapeURL(module string, addr *url.URL, metrics chan<- metric.Metric, labels map[string]string) error {
-	resp, err := exp.client.Get(addr.String())
+// scrapeTarget scrapes stats of the target
+func (exp *nginxPlusExporter) scrapeTarget(ctx context.Context, t *target, metrics chan<- metric.Metric) error {
+	if t.addr.Scheme == fileScheme {
+		return exp.scrapeFile(t, metrics)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, t.addr.String(), nil)
+	if err != nil {
+		return newScrapeError(reasonConnect, "error making HTTP request to '%s': %s", t, err)
+	}
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := t.client.Do(req.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("error making HTTP request to '%s': %s", addr.String(), err)
+		return newScrapeError(requestErrorReason(ctx, err), "error making HTTP request to '%s': %s", t, err)
 	}
 	defer resp.Body.Close()
 
 	if http.StatusOK != resp.StatusCode {
-		return fmt.Errorf("%s returned HTTP status %d", addr.String(), resp.StatusCode)
+		return newScrapeError(reasonHTTPStatus, "%s returned HTTP status %d", t, resp.StatusCode)
 	}
 
 	contentType := strings.Split(resp.Header.Get("Content-Type"), ";")[0]
 
-	if module == nginxModule {
-		err = exp.nginxScraper.Scrape(resp.Body, metrics, labels)
-		if err != nil {
-			return fmt.Errorf("error scraping nginx stats using address '%s': %s", addr.String(), err)
-		}
+	if t.module == NginxPlusModule && contentType != "application/json" {
+		return newScrapeError(reasonContentType, "%s returned unsupported content type '%s'", t, contentType)
+	}
 
-		return nil
-	} else if module == nginxPlusModule && contentType == "application/json" {
-		err = exp.nginxPlusScraper.Scrape(resp.Body, metrics, labels)
-		if err != nil {
-			return fmt.Errorf("error scraping nginx plus stats using address '%s': %s", addr.String(), err)
+	if err := exp.scrapeBody(t, resp.Body, metrics); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return newScrapeError(reasonTimeout, "%s", err)
 		}
+		return err
+	}
 
-		return nil
+	return nil
+}
+
+// scrapeBody parses stats of the target using scraper of the target module
+func (exp *nginxPlusExporter) scrapeBody(t *target, body io.Reader, metrics chan<- metric.Metric) error {
+	if err := t.scraper.Scrape(body, metrics, t.labels); err != nil {
+		if _, ok := err.(*scraper.PartialError); ok {
+			return err
+		}
+		return newScrapeError(reasonParse, "error scraping %s stats using address '%s': %s", t.module, t, err)
 	}
 
-	return fmt.Errorf("%s returned unsupported content type '%s'", addr.String(), contentType)
+	return nil
 }
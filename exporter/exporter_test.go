@@ -1,14 +1,31 @@
 package exporter_test
 
 import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/monitoring-tools/prom-nginx-exporter/exporter"
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/relabel"
 	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	. "gopkg.in/check.v1"
 )
 
@@ -207,11 +224,11 @@ func (s NginxExporterSuite) TestNginxStatsScrape_Success(c *C) {
 	client := &http.Client{Transport: NewDummyTransport(response)}
 	exp := exporter.NewNginxPlusExporter(
 		client,
-		scraper.NewNginxScraper(),
-		scraper.NewNginxPlusScraper(),
 		"nginx_test",
-		[]string{"http://localhost:9000"},
-		[]string{},
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{"http://localhost:9000"}},
+		},
+		exporter.Options{},
 	)
 
 	metrics := make(chan prometheus.Metric)
@@ -233,12 +250,9 @@ func (s NginxExporterSuite) TestNginxStatsScrape_Success(c *C) {
 	}
 
 	for m := range metrics {
-		switch m.(type) {
-		case prometheus.Gauge:
-			for metricName := range checks {
-				if strings.Contains(m.Desc().String(), metricName) {
-					checks[metricName] = true
-				}
+		for metricName := range checks {
+			if strings.Contains(m.Desc().String(), metricName) {
+				checks[metricName] = true
 			}
 		}
 	}
@@ -262,11 +276,11 @@ func (s NginxExporterSuite) TestNginxPlusStatsScrape_Success(c *C) {
 	client := &http.Client{Transport: NewDummyTransport(response)}
 	exp := exporter.NewNginxPlusExporter(
 		client,
-		scraper.NewNginxScraper(),
-		scraper.NewNginxPlusScraper(),
 		"nginx_test",
-		[]string{},
-		[]string{"http://localhost:9000"},
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{"http://localhost:9000"}},
+		},
+		exporter.Options{},
 	)
 
 	metrics := make(chan prometheus.Metric)
@@ -377,12 +391,9 @@ func (s NginxExporterSuite) TestNginxPlusStatsScrape_Success(c *C) {
 	}
 
 	for m := range metrics {
-		switch m.(type) {
-		case prometheus.Gauge:
-			for metricName := range checks {
-				if strings.Contains(m.Desc().String(), metricName) {
-					checks[metricName] = true
-				}
+		for metricName := range checks {
+			if strings.Contains(m.Desc().String(), metricName) {
+				checks[metricName] = true
 			}
 		}
 	}
@@ -394,6 +405,266 @@ func (s NginxExporterSuite) TestNginxPlusStatsScrape_Success(c *C) {
 	}
 }
 
+func (s NginxExporterSuite) TestMetricCatalog_Success(c *C) {
+	headers := http.Header{}
+	headers.Add("Content-Type", "application/json")
+	response := http.Response{
+		StatusCode: http.StatusOK,
+		Header:     headers,
+		Body:       NewDummyBody(nginxPlusStats),
+	}
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{Transport: NewDummyTransport(response)},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{"http://localhost:9000"}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	c.Assert(registry.Register(exp), IsNil, Commentf("unable to register exporter"))
+
+	families, err := registry.Gather()
+	c.Assert(err, IsNil, Commentf("metrics are inconsistent with descriptors"))
+
+	types := map[string]dto.MetricType{}
+	for _, family := range families {
+		c.Assert(family.GetHelp(), Not(Equals), "", Commentf("missed help of metric '%s'", family.GetName()))
+		types[family.GetName()] = family.GetType()
+	}
+
+	checks := map[string]dto.MetricType{
+		"nginx_test_requests_total":         dto.MetricType_COUNTER,
+		"nginx_test_zone_requests":          dto.MetricType_COUNTER,
+		"nginx_test_upstream_peer_fails":    dto.MetricType_COUNTER,
+		"nginx_test_connections_active":     dto.MetricType_GAUGE,
+		"nginx_test_upstream_peer_state":    dto.MetricType_GAUGE,
+		"nginx_test_stream_zone_received":   dto.MetricType_COUNTER,
+		"nginx_test_exporter_scrapes_total": dto.MetricType_COUNTER,
+	}
+
+	for metricName, metricType := range checks {
+		c.Assert(types[metricName], Equals, metricType, Commentf("incorrect type of metric '%s'", metricName))
+	}
+}
+
+func (s NginxExporterSuite) TestMultipleTargetsSeries_Success(c *C) {
+	plusStats := `{
+		"processes": {"respawned": 0},
+		"ssl": {},
+		"server_zones": {"first": {"requests": 10}, "second": {"requests": 20}},
+		"upstreams": {"backend": {"peers": [
+			{"server": "10.0.0.1:80", "requests": 30, "selected": 0},
+			{"server": "10.0.0.2:80", "requests": 40, "selected": 0}
+		]}}
+	}`
+
+	newServer := func(contentType string, stats string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write([]byte(stats))
+		}))
+	}
+
+	first := newServer("text/plain", nginxStats)
+	defer first.Close()
+	second := newServer("text/plain", strings.Replace(nginxStats, "Active connections: 2", "Active connections: 5", 1))
+	defer second.Close()
+	plus := newServer("application/json", plusStats)
+	defer plus.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{first.URL, second.URL}},
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{plus.URL}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	families, err := registry.Gather()
+	c.Assert(err, IsNil, Commentf("unable to gather metrics"))
+
+	values := map[string][]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			values[family.GetName()] = append(values[family.GetName()], m.GetGauge().GetValue()+m.GetCounter().GetValue())
+		}
+	}
+
+	checks := map[string][]float64{
+		"nginx_test_active":                 {2, 5},
+		"nginx_test_accepts":                {8522429, 8522429},
+		"nginx_test_zone_requests":          {10, 20},
+		"nginx_test_upstream_peer_requests": {30, 40},
+	}
+
+	for metricName, expected := range checks {
+		obtained := values[metricName]
+		sort.Float64s(obtained)
+		c.Assert(obtained, DeepEquals, expected, Commentf("incorrect series of metric '%s'", metricName))
+	}
+}
+
+func (s NginxExporterSuite) TestMetricsFilter_Success(c *C) {
+	headers := http.Header{}
+	headers.Add("Content-Type", "application/json")
+	response := http.Response{
+		StatusCode: http.StatusOK,
+		Header:     headers,
+		Body:       NewDummyBody(nginxPlusStats),
+	}
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{Transport: NewDummyTransport(response)},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{"http://localhost:9000"}},
+		},
+		exporter.Options{
+			MetricsInclude: regexp.MustCompile("^(?:cache_.*|upstream_.*)$"),
+			MetricsExclude: regexp.MustCompile("^(?:cache_.*_bytes.*|upstream_peer_healthchecks_.*)$"),
+		},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	c.Assert(registry.Register(exp), IsNil, Commentf("unable to register exporter"))
+
+	families, err := registry.Gather()
+	c.Assert(err, IsNil, Commentf("unable to gather metrics"))
+
+	names := map[string]bool{}
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+
+	for name, exposed := range map[string]bool{
+		"nginx_test_cache_hit_responses":               true,
+		"nginx_test_upstream_peer_requests":            true,
+		"nginx_test_cache_hit_bytes":                   false,
+		"nginx_test_upstream_peer_healthchecks_checks": false,
+		"nginx_test_zone_requests":                     false,
+		"nginx_test_up":                                true,
+		"nginx_test_exporter_scrapes_total":            true,
+	} {
+		c.Assert(names[name], Equals, exposed, Commentf("incorrect filtering of metric '%s'", name))
+	}
+}
+
+func (s NginxExporterSuite) TestNaNValues_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# TYPE latency summary\nlatency{quantile=\"0.5\"} NaN\nlatency_sum 0\nlatency_count 0\n" +
+			"# TYPE errors counter\nerrors NaN\n"))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.PrometheusModule, Scraper: scraper.NewPrometheusScraper(""), Urls: []string{server.URL}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "quantile")
+
+	quantile, ok := values["nginx_test_latency,quantile=0.5"]
+	c.Assert(ok, Equals, true, Commentf("NaN quantile of summary isn't exposed"))
+	c.Assert(math.IsNaN(quantile), Equals, true, Commentf("incorrect value of NaN quantile"))
+	c.Assert(values["nginx_test_latency_count"], Equals, float64(0), Commentf("incorrect count of summary"))
+
+	_, ok = values["nginx_test_errors"]
+	c.Assert(ok, Equals, false, Commentf("NaN counter is exposed"))
+}
+
+func (s NginxExporterSuite) TestRelabel_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	replacement := "web"
+	configs := []*relabel.Config{
+		{SourceLabels: []string{"__name__"}, Regex: &replacement, Action: relabel.Drop},
+		{TargetLabel: "service", Replacement: &replacement},
+		{SourceLabels: []string{"__name__"}, TargetLabel: "__name__", Replacement: &replacement, Regex: stringPtr("waiting")},
+	}
+	c.Assert(relabel.Compile(configs), IsNil, Commentf("invalid relabel configs"))
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL}},
+		},
+		exporter.Options{RelabelConfigs: configs},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	families, err := registry.Gather()
+	c.Assert(err, IsNil, Commentf("unable to gather metrics"))
+
+	services := map[string]string{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "service" {
+					services[family.GetName()] = label.GetValue()
+				}
+			}
+		}
+	}
+
+	c.Assert(services["nginx_test_active"], Equals, "web", Commentf("missed label added by relabeling"))
+	c.Assert(services["nginx_test_web"], Equals, "web", Commentf("missed metric renamed by relabeling"))
+	c.Assert(services["nginx_test_waiting"], Equals, "", Commentf("renamed metric is exposed by old name"))
+	c.Assert(services["nginx_test_up"], Equals, "", Commentf("self-monitoring metrics shouldn't be relabeled"))
+}
+
+func (s NginxExporterSuite) TestRelabelInvalidName_Fail(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	configs := []*relabel.Config{
+		{SourceLabels: []string{"__name__"}, TargetLabel: "__name__", Regex: stringPtr("(waiting)"), Replacement: stringPtr("$1-connections")},
+	}
+	c.Assert(relabel.Compile(configs), IsNil, Commentf("invalid relabel configs"))
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "#alias=web"}},
+		},
+		exporter.Options{RelabelConfigs: configs},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "section", "server")
+
+	c.Assert(values["nginx_test_up,server=web"], Equals, float64(1), Commentf("target must be up"))
+	c.Assert(values["nginx_test_active,server=web"], Equals, float64(2), Commentf("missed metrics of relabeled target"))
+	c.Assert(values["nginx_test_parse_errors_total,section=relabel,server=web"], Equals, float64(1), Commentf("metric with invalid name must be counted"))
+	_, ok := values["nginx_test_waiting,server=web"]
+	c.Assert(ok, Equals, false, Commentf("metric with invalid name is exposed by old name"))
+}
+
 func (s NginxExporterSuite) TestInvalidNginxStatsUrl_Fail(c *C) {
 	headers := http.Header{}
 	headers.Add("Content-Type", "text/plain")
@@ -406,11 +677,11 @@ func (s NginxExporterSuite) TestInvalidNginxStatsUrl_Fail(c *C) {
 	client := &http.Client{Transport: NewDummyTransport(response)}
 	exp := exporter.NewNginxPlusExporter(
 		client,
-		scraper.NewNginxScraper(),
-		scraper.NewNginxPlusScraper(),
 		"nginx_test",
-		[]string{"invalid nginx stats url"},
-		[]string{},
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{"invalid nginx stats url"}},
+		},
+		exporter.Options{},
 	)
 
 	metrics := make(chan prometheus.Metric, 1)
@@ -423,6 +694,808 @@ func (s NginxExporterSuite) TestInvalidNginxStatsUrl_Fail(c *C) {
 	c.Assert(len(metrics), Equals, 0)
 }
 
+func (s NginxExporterSuite) TestNginxStatsUnixSocketScrape_Success(c *C) {
+	socket := filepath.Join(c.MkDir(), "status.sock")
+	listener, err := net.Listen("unix", socket)
+	c.Assert(err, IsNil, Commentf("unable to listen unix socket"))
+	defer listener.Close()
+
+	requests := make(chan *http.Request, 1)
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.Write([]byte(nginxStats))
+	}))
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{Transport: &http.Transport{DisableCompression: true}},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{"unix:" + socket + ":/status"}},
+		},
+		exporter.Options{},
+	)
+
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		exp.Collect(metrics)
+		close(metrics)
+	}()
+
+	found := false
+	for m := range metrics {
+		if strings.Contains(m.Desc().String(), "nginx_test_active") {
+			c.Assert(strings.Contains(m.Desc().String(), "server"), Equals, true, Commentf("missed label 'server'"))
+			found = true
+		}
+	}
+
+	c.Assert(found, Equals, true, Commentf("didn't find metric 'nginx_test_active'"))
+	request := <-requests
+	c.Assert(request.URL.Path, Equals, "/status", Commentf("incorrect request path"))
+	c.Assert(request.Header.Get("Accept-Encoding"), Equals, "", Commentf("settings of the base transport aren't inherited"))
+}
+
+func (s NginxExporterSuite) TestNginxPlusStatsFileScrape_Success(c *C) {
+	file := filepath.Join(c.MkDir(), "status.json")
+	c.Assert(ioutil.WriteFile(file, []byte(nginxPlusStats), 0644), IsNil, Commentf("unable to write stats file"))
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{"file://" + file}},
+		},
+		exporter.Options{FileMaxAge: time.Minute},
+	)
+
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		exp.Collect(metrics)
+		close(metrics)
+	}()
+
+	found := false
+	for m := range metrics {
+		if strings.Contains(m.Desc().String(), "nginx_test_connections_active") {
+			found = true
+		}
+	}
+
+	c.Assert(found, Equals, true, Commentf("didn't find metric 'nginx_test_connections_active'"))
+}
+
+func (s NginxExporterSuite) TestNginxStatsStaleFileScrape_Fail(c *C) {
+	file := filepath.Join(c.MkDir(), "status")
+	c.Assert(ioutil.WriteFile(file, []byte(nginxStats), 0644), IsNil, Commentf("unable to write stats file"))
+
+	modified := time.Now().Add(-time.Hour)
+	c.Assert(os.Chtimes(file, modified, modified), IsNil, Commentf("unable to change modification time of stats file"))
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{"file://" + file}},
+		},
+		exporter.Options{FileMaxAge: time.Minute},
+	)
+
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		exp.Collect(metrics)
+		close(metrics)
+	}()
+
+	for m := range metrics {
+		c.Assert(strings.Contains(m.Desc().String(), "nginx_test_active"), Equals, false, Commentf("stale file shouldn't be scraped"))
+	}
+}
+
+func (s NginxExporterSuite) TestConcurrentScrape_Success(c *C) {
+	const concurrency = 2
+
+	var (
+		requests    int32
+		inFlight    int32
+		maxInFlight int32
+		release     = make(chan struct{})
+		parallel    = make(chan struct{})
+		once        sync.Once
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/hang" {
+			<-release
+			return
+		}
+
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, n) {
+				break
+			}
+		}
+
+		// the first requests wait for each other to make sure targets are scraped in parallel
+		if n == concurrency {
+			once.Do(func() { close(parallel) })
+		}
+		select {
+		case <-parallel:
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{
+				server.URL + "/status#alias=web-1",
+				server.URL + "/status#alias=web-2",
+				server.URL + "/hang",
+				server.URL + "/status#alias=web-3",
+				server.URL + "/status#alias=web-4",
+			}},
+		},
+		exporter.Options{Concurrency: concurrency, ScrapeTimeout: time.Second},
+	)
+
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		exp.Collect(metrics)
+		close(metrics)
+	}()
+
+	found := false
+	for m := range metrics {
+		if strings.Contains(m.Desc().String(), "nginx_test_active") {
+			found = true
+		}
+	}
+
+	c.Assert(found, Equals, true, Commentf("didn't find metric 'nginx_test_active'"))
+	c.Assert(atomic.LoadInt32(&requests), Equals, int32(5), Commentf("all targets should be scraped"))
+	c.Assert(atomic.LoadInt32(&maxInFlight), Equals, int32(concurrency), Commentf("targets should be scraped in parallel limited by concurrency"))
+}
+
+func (s NginxExporterSuite) TestBackgroundScrape_Success(c *C) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/status"}},
+		},
+		exporter.Options{ScrapeInterval: time.Hour},
+	)
+	exp.Start()
+	defer exp.Stop()
+
+	for i := 0; i < 100 && !strings.Contains(strings.Join(collectDescs(exp), "\n"), "nginx_test_active"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		descs := strings.Join(collectDescs(exp), "\n")
+
+		for _, metricName := range []string{"nginx_test_active", "nginx_test_target_snapshot_age_seconds"} {
+			c.Assert(strings.Contains(descs, metricName), Equals, true, Commentf("didn't find metric '%s'", metricName))
+		}
+	}
+
+	c.Assert(atomic.LoadInt32(&requests), Equals, int32(1), Commentf("collect shouldn't scrape targets in background mode"))
+}
+
+func (s NginxExporterSuite) TestBackgroundScrapeFailure_Success(c *C) {
+	var down int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/status"}},
+		},
+		exporter.Options{ScrapeInterval: 10 * time.Millisecond, SnapshotMaxAge: time.Minute},
+	)
+	exp.Start()
+	defer exp.Stop()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry)
+	for i := 0; i < 100 && values["nginx_test_up"] != 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+		values = gatherValues(c, registry)
+	}
+	c.Assert(values["nginx_test_up"], Equals, float64(1), Commentf("target isn't scraped in background"))
+
+	atomic.StoreInt32(&down, 1)
+	for i := 0; i < 100 && values["nginx_test_up"] != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		values = gatherValues(c, registry)
+	}
+	c.Assert(values["nginx_test_up"], Equals, float64(0), Commentf("failed background scrape isn't exposed"))
+
+	time.Sleep(50 * time.Millisecond)
+	values = gatherValues(c, registry)
+	c.Assert(values["nginx_test_active"], Equals, float64(2), Commentf("metrics of the last successful scrape should be kept"))
+	c.Assert(values["nginx_test_target_snapshot_age_seconds"] >= 0.05, Equals, true, Commentf("age of snapshot of failed target should grow"))
+}
+
+func (s NginxExporterSuite) TestBackgroundScrapeRetention_Success(c *C) {
+	var down int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/status"}},
+		},
+		exporter.Options{ScrapeInterval: 10 * time.Millisecond, SnapshotMaxAge: 50 * time.Millisecond},
+	)
+	exp.Start()
+	defer exp.Stop()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry)
+	c.Assert(values["nginx_test_active"], Equals, float64(2), Commentf("target isn't scraped on start"))
+
+	atomic.StoreInt32(&down, 1)
+	start := time.Now()
+	for i := 0; i < 100 && values["nginx_test_up"] != 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		values = gatherValues(c, registry)
+	}
+	c.Assert(values["nginx_test_up"], Equals, float64(0), Commentf("failed background scrape isn't exposed"))
+
+	_, kept := values["nginx_test_active"]
+	for i := 0; i < 100 && kept; i++ {
+		time.Sleep(10 * time.Millisecond)
+		values = gatherValues(c, registry)
+		_, kept = values["nginx_test_active"]
+	}
+	c.Assert(kept, Equals, false, Commentf("metrics of failed target should be dropped after maximum age of snapshot"))
+	c.Assert(time.Since(start) >= 40*time.Millisecond, Equals, true, Commentf("metrics of failed target are dropped before maximum age of snapshot"))
+	_, ok := values["nginx_test_target_snapshot_age_seconds"]
+	c.Assert(ok, Equals, false, Commentf("age of dropped snapshot is exposed"))
+	c.Assert(values["nginx_test_up"], Equals, float64(0), Commentf("up of failed target should be exposed"))
+}
+
+func (s NginxExporterSuite) TestProbe_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	handler := exporter.NewProbeHandler(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper()},
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper()},
+		},
+		exporter.Options{},
+	)
+
+	probe := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/probe?"+query, nil))
+		return recorder
+	}
+
+	resp := probe("module=stub&target=" + server.URL + "/status")
+	c.Assert(resp.Code, Equals, http.StatusOK, Commentf("incorrect status of probe"))
+	c.Assert(strings.Contains(resp.Body.String(), "nginx_test_active{"), Equals, true, Commentf("didn't find metric 'nginx_test_active'"))
+
+	c.Assert(probe("module=stub").Code, Equals, http.StatusBadRequest, Commentf("probe without target must fail"))
+	c.Assert(probe("module=unknown&target="+server.URL).Code, Equals, http.StatusBadRequest, Commentf("probe of unknown module must fail"))
+}
+
+func (s NginxExporterSuite) TestProbe_Fail(c *C) {
+	file := filepath.Join(c.MkDir(), "status")
+	c.Assert(ioutil.WriteFile(file, []byte(nginxStats), 0644), IsNil, Commentf("unable to write stats file"))
+
+	modules := []exporter.Module{{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper()}}
+	probe := func(options exporter.Options, target string) int {
+		recorder := httptest.NewRecorder()
+		exporter.NewProbeHandler(&http.Client{}, "nginx_test", modules, options).ServeHTTP(
+			recorder,
+			httptest.NewRequest(http.MethodGet, "/probe?module=stub&target="+target, nil),
+		)
+		return recorder.Code
+	}
+
+	for _, target := range []string{
+		url.QueryEscape("file://" + file),
+		url.QueryEscape("unix:/run/nginx.sock:/status"),
+		url.QueryEscape("http://localhost/status#env=prod"),
+		"http://localhost/%23zone=a",
+		url.QueryEscape("ftp://localhost/status"),
+	} {
+		c.Assert(probe(exporter.Options{}, target), Equals, http.StatusBadRequest, Commentf("probe of target '%s' must fail", target))
+	}
+
+	c.Assert(probe(exporter.Options{ProbeLocalTargets: true}, url.QueryEscape("file://"+file)), Equals, http.StatusOK, Commentf("probe of allowed local target must succeed"))
+}
+
+func (s NginxExporterSuite) TestTargetStatus_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/status", "file:///error"}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	for i := 0; i < 2; i++ {
+		values := gatherValues(c, registry, "reason", "server")
+
+		host := strings.Split(strings.TrimPrefix(server.URL, "http://"), ":")[0]
+		c.Assert(values["nginx_test_up,server="+host], Equals, float64(1), Commentf("available target must be up"))
+		c.Assert(values["nginx_test_up,server=/error"], Equals, float64(0), Commentf("unavailable target must be down"))
+		c.Assert(values["nginx_test_last_success_timestamp_seconds,server="+host] > 0, Equals, true, Commentf("missed last success of available target"))
+		c.Assert(values["nginx_test_scrape_errors_total,reason=connect,server=/error"], Equals, float64(i+1), Commentf("incorrect number of connect errors"))
+		c.Assert(values["nginx_test_scrape_errors_total,reason=connect,server="+host], Equals, float64(0), Commentf("unexpected errors of available target"))
+
+		_, ok := values["nginx_test_last_success_timestamp_seconds,server=/error"]
+		c.Assert(ok, Equals, false, Commentf("unavailable target has never succeeded"))
+	}
+}
+
+func (s NginxExporterSuite) TestTargetLabels_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{
+				server.URL + "/status#alias=web-1&env=prod&dc=eu",
+				server.URL + "/status#alias=web-2&env=dev",
+			}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "dc", "env", "server")
+
+	expected := []string{
+		"nginx_test_active,dc=eu,env=prod,server=web-1",
+		"nginx_test_active,dc=,env=dev,server=web-2",
+		"nginx_test_up,dc=eu,env=prod,server=web-1",
+		"nginx_test_up,dc=,env=dev,server=web-2",
+		"nginx_test_scrape_errors_total,dc=eu,env=prod,server=web-1",
+	}
+	for _, key := range expected {
+		_, ok := values[key]
+		c.Assert(ok, Equals, true, Commentf("missed series '%s'", key))
+	}
+}
+
+func (s NginxExporterSuite) TestTargetLabels_Fail(c *C) {
+	for _, target := range []string{
+		"http://localhost/status#port=80",
+		"http://localhost/status#1env=prod",
+		"http://localhost/status#alias=",
+		"http://localhost/status#env=prod&env=dev",
+		"http://localhost/status#env=%zz",
+		"http://localhost/status#zone=a",
+		"http://localhost/status#code=2xx",
+		"http://localhost/status#upstream=backend",
+		"http://localhost/status#serverAddress=10.0.0.1",
+		"http://localhost/status#server_zone=a",
+		"http://localhost/status#cache=http",
+		"http://localhost/status#reason=timeout",
+		"http://localhost/status#section=caches",
+	} {
+		_, err := exporter.NewExporter(&http.Client{}, "nginx_test", []exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{target}},
+		}, exporter.Options{})
+		c.Assert(err, NotNil, Commentf("invalid options of target '%s' are accepted", target))
+	}
+}
+
+func (s NginxExporterSuite) TestOfficialNaming_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plus" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(nginxPlusStats))
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/stub#alias=web-1"}},
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{server.URL + "/plus#alias=web-2"}},
+		},
+		exporter.Options{Naming: exporter.OfficialNaming},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "code", "instance", "server", "server_zone", "upstream")
+
+	expected := map[string]float64{
+		"nginx_connections_active,instance=web-1":                                                                 2,
+		"nginx_http_requests_total,instance=web-1":                                                                8641727,
+		"nginx_up,instance=web-1":                                                                                 1,
+		"nginxplus_http_requests_current,instance=web-2":                                                          98,
+		"nginxplus_server_zone_responses,code=2xx,instance=web-2,server_zone=zone.a_80":                           222,
+		"nginxplus_upstream_server_state,instance=web-2,server=1.2.3.123:80,upstream=first_upstream":              1,
+		"nginxplus_upstream_server_limit,instance=web-2,server=1.2.3.123:80,upstream=first_upstream":              1000000,
+		"nginxplus_upstream_server_responses,code=5xx,instance=web-2,server=1.2.3.123:80,upstream=first_upstream": 5555,
+		"nginx_up,instance=web-2":                                                                                 1,
+	}
+	for key, value := range expected {
+		actual, ok := values[key]
+		c.Assert(ok, Equals, true, Commentf("missed series '%s'", key))
+		c.Assert(actual, Equals, value, Commentf("incorrect value of series '%s'", key))
+	}
+
+	for key := range values {
+		c.Assert(strings.Contains(key, "zone_responses_2xx"), Equals, false, Commentf("unexpected series '%s'", key))
+		c.Assert(strings.HasPrefix(key, "nginx_active"), Equals, false, Commentf("unexpected series '%s'", key))
+	}
+}
+
+func (s NginxExporterSuite) TestCounterContinuity_Success(c *C) {
+	var (
+		accepts = []int{100, 50, 70}
+		loads   = []int{1000, 1000, 2000}
+		scrapes int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.LoadInt32(&scrapes)
+		if r.URL.Path == "/plus" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"generation": 0, "load_timestamp": %d, "requests": {"total": %d, "current": 1}}`, loads[i], 300+100*i)
+			return
+		}
+		fmt.Fprintf(w, "Active connections: 2\n server accepts handled requests\n%d %d %d\nReading: 0 Writing: 1 Waiting: 3", accepts[i], accepts[i], accepts[i])
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/stub#alias=stub"}},
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{server.URL + "/plus#alias=plus"}},
+		},
+		exporter.Options{CounterContinuity: true},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	expected := []map[string]float64{
+		{"nginx_test_accepts,server=stub": 100, "nginx_test_active,server=stub": 2, "nginx_test_resets_total,server=stub": 0, "nginx_test_requests_total,server=plus": 300, "nginx_test_requests_current,server=plus": 1, "nginx_test_resets_total,server=plus": 0},
+		{"nginx_test_accepts,server=stub": 150, "nginx_test_active,server=stub": 2, "nginx_test_resets_total,server=stub": 1, "nginx_test_requests_total,server=plus": 400, "nginx_test_requests_current,server=plus": 1, "nginx_test_resets_total,server=plus": 0},
+		{"nginx_test_accepts,server=stub": 170, "nginx_test_active,server=stub": 2, "nginx_test_resets_total,server=stub": 1, "nginx_test_requests_total,server=plus": 900, "nginx_test_requests_current,server=plus": 1, "nginx_test_resets_total,server=plus": 1},
+	}
+
+	for i := range accepts {
+		atomic.StoreInt32(&scrapes, int32(i))
+
+		values := gatherValues(c, registry, "server")
+
+		for key, value := range expected[i] {
+			c.Assert(values[key], Equals, value, Commentf("incorrect value of '%s' on scrape %d", key, i))
+		}
+	}
+}
+
+func (s NginxExporterSuite) TestInherit_Success(c *C) {
+	var accepts int32 = 100
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		n := atomic.LoadInt32(&accepts)
+		fmt.Fprintf(w, "Active connections: 2\n server accepts handled requests\n%d %d %d\nReading: 0 Writing: 1 Waiting: 3", n, n, n)
+	}))
+	defer server.Close()
+
+	options := exporter.Options{CounterContinuity: true}
+	modules := []exporter.Module{{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/stub#alias=stub", server.URL + "/down#alias=down"}}}
+
+	previous := exporter.NewNginxPlusExporter(&http.Client{}, "nginx_test", modules, options)
+	collectDescs(previous)
+
+	atomic.StoreInt32(&accepts, 50)
+	exp := exporter.NewNginxPlusExporter(&http.Client{}, "nginx_test", modules, options)
+	exp.Inherit(previous)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "reason", "server")
+
+	c.Assert(values["nginx_test_accepts,server=stub"], Equals, float64(150), Commentf("continuity of counters isn't inherited"))
+	c.Assert(values["nginx_test_resets_total,server=stub"], Equals, float64(1), Commentf("reset after inherited values isn't counted"))
+	c.Assert(values["nginx_test_scrape_errors_total,reason=http_status,server=down"], Equals, float64(2), Commentf("errors of the previous exporter aren't inherited"))
+	c.Assert(values["nginx_test_last_success_timestamp_seconds,server=down"], Equals, float64(0), Commentf("failed target has time of the last success"))
+}
+
+type panicScraper struct{}
+
+func (scr panicScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	var peer *struct{ Selected *int64 }
+	metrics <- metric.NewMetric("selected", float64(*peer.Selected), labels)
+	return nil
+}
+
+func (s NginxExporterSuite) TestScrapePanic_Fail(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plus" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"version": 8, "connections": {"accepted": 1}, "caches": [1]}`))
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxModule, Scraper: panicScraper{}, Urls: []string{server.URL + "/panic#alias=panic"}},
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{server.URL + "/plus#alias=plus"}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "reason", "section", "server")
+
+	c.Assert(values["nginx_test_up,server=panic"], Equals, float64(0), Commentf("panicked target must be down"))
+	c.Assert(values["nginx_test_scrape_errors_total,reason=parse,server=panic"], Equals, float64(1), Commentf("panic must be counted as parse error"))
+	c.Assert(values["nginx_test_up,server=plus"], Equals, float64(1), Commentf("partially parsed target must be up"))
+	c.Assert(values["nginx_test_connections_accepted,server=plus"], Equals, float64(1), Commentf("missed metrics of partially parsed target"))
+	c.Assert(values["nginx_test_parse_errors_total,section=caches,server=plus"], Equals, float64(1), Commentf("skipped section must be counted"))
+}
+
+func (s NginxExporterSuite) TestUnknownFields_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"version": 8,
+			"slabs": {},
+			"connections": {"accepted": 1},
+			"server_zones": {"a": {"requests": 1, "ssl": {}, "limit": 1}, "b": {"requests": 1, "ssl": {}}}
+		}`))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(scraper.WithStrict(true)), Urls: []string{server.URL + "#alias=plus"}},
+		},
+		exporter.Options{},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(exp)
+
+	for i := 0; i < 2; i++ {
+		values := gatherValues(c, registry, "section")
+
+		c.Assert(values["nginx_test_up"], Equals, float64(1), Commentf("target with unknown fields must be up"))
+		c.Assert(values["nginx_test_connections_accepted"], Equals, float64(1), Commentf("missed metrics of target with unknown fields"))
+		c.Assert(values["nginx_test_unknown_fields,section=server_zones"], Equals, float64(2), Commentf("incorrect number of unknown fields of server zones"))
+		c.Assert(values["nginx_test_unknown_fields,section=slabs"], Equals, float64(1), Commentf("incorrect number of unknown fields of slabs"))
+	}
+}
+
+func (s NginxExporterSuite) TestConfiguredTargets_Success(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{{
+			Name:    exporter.NginxModule,
+			Scraper: scraper.NewNginxScraper(),
+			Targets: []exporter.Target{
+				{URL: server.URL + "/status#env=dev", Alias: "web-1", Labels: map[string]string{"env": "prod"}, Headers: map[string]string{"Authorization": "Bearer token"}},
+				{URL: server.URL + "/status", Alias: "web-2"},
+				{URL: server.URL + "/slow", Alias: "web-3", Headers: map[string]string{"Authorization": "Bearer token"}, ScrapeTimeout: 50 * time.Millisecond},
+			},
+		}},
+		exporter.Options{ScrapeTimeout: time.Second},
+	)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(exp)
+
+	values := gatherValues(c, registry, "env", "reason", "server")
+
+	c.Assert(values["nginx_test_up,env=prod,server=web-1"], Equals, float64(1), Commentf("target with headers must be up"))
+	c.Assert(values["nginx_test_up,env=,server=web-2"], Equals, float64(0), Commentf("target without headers must be down"))
+	c.Assert(values["nginx_test_scrape_errors_total,env=,reason=http_status,server=web-2"], Equals, float64(1), Commentf("unauthorized scrape isn't counted"))
+	c.Assert(values["nginx_test_scrape_errors_total,env=,reason=timeout,server=web-3"], Equals, float64(1), Commentf("scrape timeout of target isn't applied"))
+}
+
+func (s NginxExporterSuite) TestNewExporter_Fail(c *C) {
+	for _, module := range []exporter.Module{
+		{Name: exporter.NginxModule, Urls: []string{"http://localhost/status#port=80"}},
+		{Name: exporter.NginxModule, Urls: []string{"unix::/status"}},
+		{Name: exporter.NginxModule, Targets: []exporter.Target{{URL: "http://localhost/status", Labels: map[string]string{"1env": "prod"}}}},
+		{Name: exporter.NginxModule, Targets: []exporter.Target{{URL: "http://localhost/status", Labels: map[string]string{"alias": "web-1"}}}},
+	} {
+		_, err := exporter.NewExporter(&http.Client{}, "nginx_test", []exporter.Module{module}, exporter.Options{})
+		c.Assert(err, NotNil, Commentf("invalid module %+v is accepted", module))
+	}
+
+	_, err := exporter.NewExporter(&http.Client{}, "nginx_test", []exporter.Module{
+		{Name: exporter.NginxModule, Urls: []string{"http://localhost/status#alias=web-1"}, Targets: []exporter.Target{{URL: "http://localhost/status", Alias: "web-2"}}},
+	}, exporter.Options{})
+	c.Assert(err, IsNil, Commentf("valid module is rejected"))
+}
+
+func (s NginxExporterSuite) TestDuplicateTargets_Fail(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer server.Close()
+
+	for _, modules := range [][]exporter.Module{
+		{{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/a", server.URL + "/b"}}},
+		{
+			{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/a"}},
+			{Name: exporter.HealthcheckModule, Scraper: scraper.NewHealthcheckScraper(), Urls: []string{server.URL + "/b"}},
+		},
+	} {
+		_, err := exporter.NewExporter(&http.Client{}, "nginx_test", modules, exporter.Options{})
+		c.Assert(err, NotNil, Commentf("targets with the same labels are accepted"))
+		c.Assert(strings.Contains(err.Error(), "have the same labels"), Equals, true, Commentf("incorrect error message: %s", err))
+	}
+
+	exp, err := exporter.NewExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: []string{server.URL + "/a#alias=a", server.URL + "/b#alias=b"}}},
+		exporter.Options{},
+	)
+	c.Assert(err, IsNil, Commentf("targets with distinct aliases are rejected"))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exp)
+	_, err = registry.Gather()
+	c.Assert(err, IsNil, Commentf("unable to gather metrics of targets on the same host"))
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// gatherValues gathers metrics of the registry and returns values of series by keys of the name of family followed
+// by the passed labels, e.g. "nginx_test_up,server=web-1" for labels "server"
+func gatherValues(c *C, gatherer prometheus.Gatherer, labelNames ...string) map[string]float64 {
+	families, err := gatherer.Gather()
+	c.Assert(err, IsNil, Commentf("unable to gather metrics"))
+
+	keyed := make(map[string]bool, len(labelNames))
+	for _, name := range labelNames {
+		keyed[name] = true
+	}
+
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				if keyed[label.GetName()] {
+					key += "," + label.GetName() + "=" + label.GetValue()
+				}
+			}
+			values[key] = m.GetGauge().GetValue() + m.GetCounter().GetValue()
+		}
+	}
+
+	return values
+}
+
+// collectDescs collects metrics of the exporter and returns their descriptions
+func collectDescs(exp prometheus.Collector) []string {
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		exp.Collect(metrics)
+		close(metrics)
+	}()
+
+	descs := []string{}
+	for m := range metrics {
+		descs = append(descs, m.Desc().String())
+	}
+
+	return descs
+}
+
 type DummyTransport struct {
 	response http.Response
 }
@@ -446,3 +1519,59 @@ func NewDummyBody(body string) DummyBody {
 func (body DummyBody) Close() error {
 	return nil
 }
+
+func BenchmarkCollect(b *testing.B) {
+	dir, err := ioutil.TempDir("", "benchmark")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "status.json")
+	if err := ioutil.WriteFile(file, []byte(generateNginxPlusStats(50, 20)), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	exp := exporter.NewNginxPlusExporter(
+		&http.Client{},
+		"nginx_test",
+		[]exporter.Module{
+			{Name: exporter.NginxPlusModule, Scraper: scraper.NewNginxPlusScraper(), Urls: []string{"file://" + file}},
+		},
+		exporter.Options{},
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		metrics := make(chan prometheus.Metric, 1000)
+		go func() {
+			exp.Collect(metrics)
+			close(metrics)
+		}()
+
+		for range metrics {
+		}
+	}
+}
+
+// generateNginxPlusStats generates nginx plus stats with the number of server zones and upstreams with peers
+func generateNginxPlusStats(upstreams int, peers int) string {
+	var zones, ups []string
+	for i := 0; i < upstreams; i++ {
+		zones = append(zones, fmt.Sprintf(`"zone%d": {"processing": 1, "requests": 2, "responses": {"1xx": 1, "2xx": 2, "3xx": 3, "4xx": 4, "5xx": 5, "total": 15}, "received": 3, "sent": 4}`, i))
+
+		var ps []string
+		for j := 0; j < peers; j++ {
+			ps = append(ps, fmt.Sprintf(`{"id": %d, "server": "10.0.%d.%d:80", "backup": false, "weight": 1, "state": "up", "active": 1, "requests": 10, "responses": {"1xx": 1, "2xx": 2, "3xx": 3, "4xx": 4, "5xx": 5, "total": 15}, "sent": 1, "received": 2, "fails": 0, "unavail": 0, "health_checks": {"checks": 1, "fails": 0, "unhealthy": 0, "last_passed": true}, "downtime": 0, "downstart": 0, "selected": 1451606400000, "header_time": 5, "response_time": 7}`, j, i, j))
+		}
+		ups = append(ups, fmt.Sprintf(`"upstream%d": {"peers": [%s], "keepalive": 0, "zombies": 0}`, i, strings.Join(ps, ", ")))
+	}
+
+	return fmt.Sprintf(
+		`{"version": 6, "processes": {"respawned": 0}, "connections": {"accepted": 1, "dropped": 0, "active": 1, "idle": 1}, "ssl": {"handshakes": 1}, "requests": {"total": 1, "current": 1}, "server_zones": {%s}, "upstreams": {%s}, "caches": {}, "stream": {"server_zones": {}, "upstreams": {}}}`,
+		strings.Join(zones, ", "),
+		strings.Join(ups, ", "),
+	)
+}
@@ -0,0 +1,31 @@
+package exporter
+
+import (
+	"os"
+	"time"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+)
+
+// fileScheme is used to define targets with stats written to the file(example: file:///var/lib/nginx/status.json)
+const fileScheme = "file"
+
+// scrapeFile scrapes stats of the target from the file, the stale file is treated as unavailable target
+func (exp *nginxPlusExporter) scrapeFile(t *target, metrics chan<- metric.Metric) error {
+	file, err := os.Open(t.addr.Path)
+	if err != nil {
+		return newScrapeError(reasonConnect, "error opening stats file '%s': %s", t, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return newScrapeError(reasonConnect, "error reading stats file '%s': %s", t, err)
+	}
+
+	if age := time.Since(info.ModTime()); exp.options.FileMaxAge > 0 && age > exp.options.FileMaxAge {
+		return newScrapeError(reasonConnect, "stats file '%s' is stale: modified %s ago", t, age)
+	}
+
+	return exp.scrapeBody(t, file, metrics)
+}
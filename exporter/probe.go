@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// moduleAliases are short names of modules accepted by the probe handler
+var moduleAliases = map[string]string{
+	"stub": NginxModule,
+	"plus": NginxPlusModule,
+}
+
+// ResolveModule returns the name of module by its name or short alias(stub, plus)
+func ResolveModule(name string) string {
+	if module, ok := moduleAliases[name]; ok {
+		return module
+	}
+
+	return name
+}
+
+// probeHandler scrapes the single target passed in request and serves its metrics
+type probeHandler struct {
+	client    *http.Client
+	namespace string
+	scrapers  map[string]scraper.Scraper
+	options   Options
+}
+
+// NewProbeHandler creates handler of requests like /probe?target=<url>&module=<module>,
+// the target is scraped on every request by scraper of the module, urls of modules are ignored
+func NewProbeHandler(client *http.Client, namespace string, modules []Module, options Options) http.Handler {
+	scrapers := make(map[string]scraper.Scraper, len(modules))
+	for _, module := range modules {
+		scrapers[module.Name] = module.Scraper
+	}
+
+	options.ScrapeInterval = 0
+
+	return &probeHandler{
+		client:    client,
+		namespace: namespace,
+		scrapers:  scrapers,
+		options:   options,
+	}
+}
+
+// checkTarget checks that the target passed in request is the http or https url without options, so clients
+// of the handler can't read local files, dial unix sockets or set labels, unless local targets are allowed by options
+func (h *probeHandler) checkTarget(rawURL string) error {
+	if strings.Contains(rawURL, targetOptionsSeparator) {
+		return fmt.Errorf("options of target '%s' aren't allowed in probes", rawURL)
+	}
+
+	addr, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("unable to parse address '%s': %s", rawURL, err)
+	}
+
+	switch {
+	case addr.Scheme == "http" || addr.Scheme == "https":
+		return nil
+	case h.options.ProbeLocalTargets && (addr.Scheme == fileScheme || strings.HasPrefix(rawURL, unixSocketPrefix)):
+		return nil
+	default:
+		return fmt.Errorf("scheme of target '%s' isn't allowed in probes", rawURL)
+	}
+}
+
+// ServeHTTP scrapes the target using fresh registry and serves its metrics
+func (h *probeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	rawURL := query.Get("target")
+	if rawURL == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	module := ResolveModule(query.Get("module"))
+	if module == "" {
+		module = NginxModule
+	}
+
+	scr, ok := h.scrapers[module]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module '%s'", module), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.checkTarget(rawURL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t, err := newTarget(module, rawURL, h.client)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.scraper = scr
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(newExporter(h.namespace, []*target{t}, h.options)); err != nil {
+		http.Error(w, fmt.Sprintf("unable to scrape target '%s': %s", rawURL, err), http.StatusBadRequest)
+		return
+	}
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
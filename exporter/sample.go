@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sample is the metric of the family, label pairs are shared between samples of the same collect
+type sample struct {
+	family *family
+	value  float64
+	labels []*dto.LabelPair
+}
+
+// Desc returns descriptor of the family of the sample
+func (s *sample) Desc() *prometheus.Desc {
+	return s.family.desc
+}
+
+// Write writes the sample in protobuf format
+func (s *sample) Write(out *dto.Metric) error {
+	out.Label = s.labels
+
+	if s.family.valueType == prometheus.CounterValue {
+		out.Counter = &dto.Counter{Value: proto.Float64(s.value)}
+	} else {
+		out.Gauge = &dto.Gauge{Value: proto.Float64(s.value)}
+	}
+
+	return nil
+}
+
+// hash returns FNV-1a hash of the name and labels of the sample identifying its series
+func (s *sample) hash(name string) uint64 {
+	const (
+		offset    = 14695981039346656037
+		prime     = 1099511628211
+		separator = 0xff
+	)
+
+	hash := uint64(offset)
+	add := func(s string) {
+		for i := 0; i < len(s); i++ {
+			hash ^= uint64(s[i])
+			hash *= prime
+		}
+		hash ^= separator
+		hash *= prime
+	}
+
+	add(name)
+	for _, pair := range s.labels {
+		add(pair.GetName())
+		add(pair.GetValue())
+	}
+
+	return hash
+}
+
+// labelPairs caches label pairs by name and value to share them between samples
+type labelPairs map[[2]string]*dto.LabelPair
+
+// get returns the cached label pair, the pair is created on first use
+func (p labelPairs) get(name string, value string) *dto.LabelPair {
+	key := [2]string{name, value}
+
+	pair, ok := p[key]
+	if !ok {
+		pair = &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)}
+		p[key] = pair
+	}
+
+	return pair
+}
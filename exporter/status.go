@@ -0,0 +1,258 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// reasonConnect is the reason of errors of connecting to the target or opening stats file
+	reasonConnect = "connect"
+	// reasonTimeout is the reason of scrapes exceeded the scrape timeout
+	reasonTimeout = "timeout"
+	// reasonHTTPStatus is the reason of responses with unexpected HTTP status
+	reasonHTTPStatus = "http_status"
+	// reasonContentType is the reason of responses with unsupported content type
+	reasonContentType = "content_type"
+	// reasonParse is the reason of errors of parsing stats
+	reasonParse = "parse"
+)
+
+// errorReasons are all reasons of scrape errors, counters of every reason are initialized for every target
+var errorReasons = []string{reasonConnect, reasonTimeout, reasonHTTPStatus, reasonContentType, reasonParse}
+
+// relabelSection is the section of parse errors counting metrics dropped due to invalid names produced by relabeling
+const relabelSection = "relabel"
+
+// scrapeError is the error of scraping the target with the reason of failure
+type scrapeError struct {
+	reason string
+	err    error
+}
+
+// newScrapeError creates scrape error with the reason and formatted message
+func newScrapeError(reason string, format string, args ...interface{}) error {
+	return &scrapeError{reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+// Error returns message of the error
+func (e *scrapeError) Error() string {
+	return e.err.Error()
+}
+
+// errorReason returns the reason of the scrape error, errors without reason are treated as parse errors
+func errorReason(err error) string {
+	if e, ok := err.(*scrapeError); ok {
+		return e.reason
+	}
+
+	return reasonParse
+}
+
+// requestErrorReason returns the reason of the failed request, the request is timed out
+// either by the deadline of the context or by timeouts of the client
+func requestErrorReason(ctx context.Context, err error) string {
+	if ctx.Err() == context.DeadlineExceeded {
+		return reasonTimeout
+	}
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return reasonTimeout
+	}
+
+	return reasonConnect
+}
+
+// targetStatus is the set of metrics describing the state of scraping every target
+type targetStatus struct {
+	up          *prometheus.Desc
+	duration    *prometheus.Desc
+	lastSuccess *prometheus.Desc
+	unknown     *prometheus.Desc
+	errors      *prometheus.CounterVec
+	parseErrors *prometheus.CounterVec
+	resets      *prometheus.CounterVec
+	labelNames  []string
+}
+
+// newTargetStatus creates metrics of state of scraping the targets with the passed labels of targets,
+// resets of counters of targets are counted only when they are kept monotonic
+func newTargetStatus(namespace string, targets []*target, labelNames []string, resets bool) *targetStatus {
+	status := &targetStatus{
+		labelNames: labelNames,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "up"),
+			"Whether the last scrape of the target was successful.",
+			labelNames,
+			nil,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "scrape_duration_seconds"),
+			"Duration of the last scrape of the target.",
+			labelNames,
+			nil,
+		),
+		lastSuccess: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "last_success_timestamp_seconds"),
+			"Unix timestamp of the last successful scrape of the target.",
+			labelNames,
+			nil,
+		),
+		unknown: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "unknown_fields"),
+			"Number of fields of sections of stats of the target unknown to the scraper in the last scrape.",
+			append(append([]string(nil), labelNames...), "section"),
+			nil,
+		),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_errors_total",
+			Help:      "Total number of failed scrapes of the target by reason.",
+		}, append(append([]string(nil), labelNames...), "reason")),
+		parseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "parse_errors_total",
+			Help:      "Total number of values of sections of stats of the target skipped due to unexpected types.",
+		}, append(append([]string(nil), labelNames...), "section")),
+	}
+
+	if resets {
+		status.resets = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resets_total",
+			Help:      "Total number of detected resets of counters of the target.",
+		}, labelNames)
+	}
+
+	for _, t := range targets {
+		for _, reason := range errorReasons {
+			status.errors.WithLabelValues(append(t.labelValues(labelNames), reason)...)
+		}
+		if status.resets != nil {
+			status.resets.WithLabelValues(t.labelValues(labelNames)...)
+		}
+	}
+
+	return status
+}
+
+// inherit adds counters of the previous target in the previous status to counters of the target
+func (s *targetStatus) inherit(previous *targetStatus, t, previousTarget *target) {
+	var (
+		values         = t.labelValues(s.labelNames)
+		previousValues = previousTarget.labelValues(previous.labelNames)
+	)
+
+	inheritCounters(s.errors, previous.errors, values, previous.labelNames, previousValues, "reason")
+	inheritCounters(s.parseErrors, previous.parseErrors, values, previous.labelNames, previousValues, "section")
+	if s.resets != nil && previous.resets != nil {
+		inheritCounters(s.resets, previous.resets, values, previous.labelNames, previousValues, "")
+	}
+}
+
+// inheritCounters adds counters of the previous vector labelled by previous values to counters of the vector
+// labelled by values and the value of the extra label of the previous counter
+func inheritCounters(vec, previous *prometheus.CounterVec, values, previousNames, previousValues []string, extra string) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		previous.Collect(metrics)
+		close(metrics)
+	}()
+
+	for m := range metrics {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+
+		labels := make(map[string]string, len(pb.Label))
+		for _, pair := range pb.Label {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+
+		matched := true
+		for i, name := range previousNames {
+			matched = matched && labels[name] == previousValues[i]
+		}
+		if !matched {
+			continue
+		}
+
+		counterValues := values
+		if extra != "" {
+			counterValues = append(append([]string(nil), values...), labels[extra])
+		}
+		vec.WithLabelValues(counterValues...).Add(pb.GetCounter().GetValue())
+	}
+}
+
+// describe describes metrics of state of scraping the targets
+func (s *targetStatus) describe(ch chan<- *prometheus.Desc) {
+	ch <- s.up
+	ch <- s.duration
+	ch <- s.lastSuccess
+	ch <- s.unknown
+	s.errors.Describe(ch)
+	s.parseErrors.Describe(ch)
+	if s.resets != nil {
+		s.resets.Describe(ch)
+	}
+}
+
+// observe counts the error of scraping the target
+func (s *targetStatus) observe(t *target, err error) {
+	if err != nil {
+		s.errors.WithLabelValues(append(t.labelValues(s.labelNames), errorReason(err))...).Inc()
+	}
+}
+
+// observeParseErrors counts values of sections of stats of the target skipped while parsing
+func (s *targetStatus) observeParseErrors(t *target, sections []string) {
+	for _, section := range sections {
+		s.parseErrors.WithLabelValues(append(t.labelValues(s.labelNames), section)...).Inc()
+	}
+}
+
+// observeReset counts the reset of counters of the target
+func (s *targetStatus) observeReset(t *target) {
+	s.resets.WithLabelValues(t.labelValues(s.labelNames)...).Inc()
+}
+
+// expose exposes state of scraping every target from the scrape results
+func (s *targetStatus) expose(ch chan<- prometheus.Metric, targets []*target, results []scrapeResult) {
+	for i, result := range results {
+		var (
+			values = targets[i].labelValues(s.labelNames)
+			up     = 1.0
+		)
+
+		if result.err != nil {
+			up = 0
+		}
+
+		ch <- prometheus.MustNewConstMetric(s.up, prometheus.GaugeValue, up, values...)
+		ch <- prometheus.MustNewConstMetric(s.duration, prometheus.GaugeValue, result.duration.Seconds(), values...)
+
+		if !result.lastSuccess.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				s.lastSuccess,
+				prometheus.GaugeValue,
+				float64(result.lastSuccess.UnixNano())/1e9,
+				values...,
+			)
+		}
+
+		for section, count := range result.unknownFields {
+			ch <- prometheus.MustNewConstMetric(s.unknown, prometheus.GaugeValue, float64(count), append(values, section)...)
+		}
+	}
+
+	s.errors.Collect(ch)
+	s.parseErrors.Collect(ch)
+	if s.resets != nil {
+		s.resets.Collect(ch)
+	}
+}
@@ -0,0 +1,338 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+)
+
+const (
+	// unixSocketPrefix is used to define targets listening on unix domain socket(example: unix:/run/nginx.sock:/status)
+	unixSocketPrefix = "unix:"
+	// unixSocketHost is the host sent in requests to unix domain socket targets
+	unixSocketHost = "localhost"
+	// targetOptionsSeparator separates the url of target from its options(example: http://127.0.0.1:8080/status#alias=web-1&env=prod)
+	targetOptionsSeparator = "#"
+	// aliasOption is the option of target replacing the value of "server" label
+	aliasOption = "alias"
+)
+
+var (
+	// targetLabelNames are names of labels identifying the target added to all metrics of the target
+	targetLabelNames = []string{"server", "port"}
+	// officialTargetLabelNames are names of labels identifying the target in official naming scheme,
+	// where "server" label is the address of upstream peer
+	officialTargetLabelNames = []string{"instance", "port"}
+	// reservedLabelNames are names of labels which can't be used as static labels of targets: labels identifying
+	// the target, labels of metrics of the state of scraping and labels of catalogs of scrapers
+	reservedLabelNames = newReservedLabelNames("server", "instance", "port", "reason", "section")
+	// labelNameRegexp matches valid names of labels
+	labelNameRegexp = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+)
+
+// newReservedLabelNames creates the set of the passed names and names of labels of catalogs of scrapers
+func newReservedLabelNames(names ...string) map[string]bool {
+	reserved := map[string]bool{}
+	for _, name := range append(names, scraper.CatalogLabelNames()...) {
+		reserved[name] = true
+	}
+
+	return reserved
+}
+
+// target is a single stats endpoint of nginx or nginx plus
+type target struct {
+	module  string
+	raw     string
+	addr    *url.URL
+	client  *http.Client
+	scraper scraper.Scraper
+	labels  map[string]string
+	headers map[string]string
+	timeout time.Duration
+}
+
+// newTargets creates targets for the stats urls of the module
+func newTargets(module Module, client *http.Client) ([]*target, error) {
+	targets := make([]*target, 0, len(module.Urls)+len(module.Targets))
+	for _, u := range module.Urls {
+		t, err := newTarget(module.Name, u, client)
+		if err != nil {
+			return nil, err
+		}
+		t.scraper = module.Scraper
+		targets = append(targets, t)
+	}
+
+	for _, config := range module.Targets {
+		t, err := newConfiguredTarget(module.Name, config, client)
+		if err != nil {
+			return nil, err
+		}
+		t.scraper = module.Scraper
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
+
+// newConfiguredTarget creates target for the stats url with its own settings, the alias and labels of settings
+// override options of the url
+func newConfiguredTarget(module string, config Target, client *http.Client) (*target, error) {
+	if config.Client != nil {
+		client = config.Client
+	}
+
+	t, err := newTarget(module, config.URL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range config.Labels {
+		if name == aliasOption {
+			return nil, fmt.Errorf("invalid label name '%s' of target '%s'", name, config.URL)
+		}
+		if err := t.setOption(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Alias != "" {
+		t.setOption(aliasOption, config.Alias)
+	}
+
+	t.headers = config.Headers
+	t.timeout = config.ScrapeTimeout
+
+	return t, nil
+}
+
+// newTarget creates target for the passed stats url with optional alias and static labels after "#",
+// targets on unix domain socket get a dedicated client
+func newTarget(module string, rawURL string, client *http.Client) (*target, error) {
+	rawURL, options, err := splitTargetOptions(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var t *target
+	if strings.HasPrefix(rawURL, unixSocketPrefix) {
+		t, err = newUnixSocketTarget(module, rawURL, client)
+	} else {
+		t, err = newURLTarget(module, rawURL, client)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range options {
+		if err := t.setOption(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// setOption sets the static label of the target or replaces the value of "server" label by the alias
+func (t *target) setOption(name, value string) error {
+	if name != aliasOption && (reservedLabelNames[name] || !labelNameRegexp.MatchString(name)) {
+		return fmt.Errorf("invalid label name '%s' of target '%s'", name, t)
+	}
+	if name == aliasOption && value == "" {
+		return fmt.Errorf("empty alias of target '%s'", t)
+	}
+
+	if name == aliasOption {
+		name = "server"
+	}
+	t.labels[name] = value
+
+	return nil
+}
+
+// splitTargetOptions splits the url of target and its options in query format, options are the alias
+// and static labels of the target
+func splitTargetOptions(rawURL string) (string, map[string]string, error) {
+	i := strings.Index(rawURL, targetOptionsSeparator)
+	if i < 0 {
+		return rawURL, nil, nil
+	}
+
+	values, err := url.ParseQuery(rawURL[i+1:])
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to parse options of target '%s': %s", rawURL, err)
+	}
+
+	options := make(map[string]string, len(values))
+	for name, value := range values {
+		if len(value) != 1 {
+			return "", nil, fmt.Errorf("option '%s' of target '%s' is set %d times", name, rawURL, len(value))
+		}
+		options[name] = value[0]
+	}
+
+	return rawURL[:i], options, nil
+}
+
+// newURLTarget creates target for the passed stats url
+func newURLTarget(module string, rawURL string, client *http.Client) (*target, error) {
+	addr, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse address '%s': %s", rawURL, err)
+	}
+
+	labels := map[string]string{
+		"port":   addr.Port(),
+		"server": addr.Hostname(),
+	}
+
+	if addr.Scheme == fileScheme {
+		labels["server"] = addr.Path
+	}
+
+	return &target{
+		module: module,
+		raw:    rawURL,
+		addr:   addr,
+		client: client,
+		labels: labels,
+	}, nil
+}
+
+// newUnixSocketTarget creates target for the url in format unix:<socket path>:<request path>
+func newUnixSocketTarget(module string, rawURL string, client *http.Client) (*target, error) {
+	socket, path := strings.TrimPrefix(rawURL, unixSocketPrefix), "/"
+	if i := strings.Index(socket, ":"); i >= 0 {
+		socket, path = socket[:i], socket[i+1:]
+	}
+
+	if socket == "" {
+		return nil, fmt.Errorf("unable to parse address '%s': empty unix socket path", rawURL)
+	}
+
+	addr, err := url.Parse("http://" + unixSocketHost + path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse address '%s': %s", rawURL, err)
+	}
+
+	return &target{
+		module: module,
+		raw:    rawURL,
+		addr:   addr,
+		client: newUnixSocketClient(socket, client),
+		labels: map[string]string{
+			"port":   "",
+			"server": socket,
+		},
+	}, nil
+}
+
+// newUnixSocketClient creates http client dialing the unix socket, settings of the transport and the timeout
+// are inherited from the base client
+func newUnixSocketClient(socket string, base *http.Client) *http.Client {
+	var dialer net.Dialer
+
+	baseTransport, ok := base.Transport.(*http.Transport)
+	if !ok {
+		baseTransport = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport := &http.Transport{
+		Proxy: baseTransport.Proxy,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+		TLSClientConfig:        baseTransport.TLSClientConfig,
+		TLSHandshakeTimeout:    baseTransport.TLSHandshakeTimeout,
+		DisableKeepAlives:      baseTransport.DisableKeepAlives,
+		DisableCompression:     baseTransport.DisableCompression,
+		MaxIdleConns:           baseTransport.MaxIdleConns,
+		MaxIdleConnsPerHost:    baseTransport.MaxIdleConnsPerHost,
+		IdleConnTimeout:        baseTransport.IdleConnTimeout,
+		ResponseHeaderTimeout:  baseTransport.ResponseHeaderTimeout,
+		ExpectContinueTimeout:  baseTransport.ExpectContinueTimeout,
+		TLSNextProto:           baseTransport.TLSNextProto,
+		ProxyConnectHeader:     baseTransport.ProxyConnectHeader,
+		MaxResponseHeaderBytes: baseTransport.MaxResponseHeaderBytes,
+	}
+
+	return &http.Client{Transport: transport, Timeout: base.Timeout}
+}
+
+// useOfficialNaming switches the target to official naming scheme, the target is identified by "instance" label
+// instead of "server" label
+func (t *target) useOfficialNaming() {
+	t.scraper = scraper.NewOfficialScraper(t.scraper)
+	t.labels["instance"] = t.labels["server"]
+	delete(t.labels, "server")
+}
+
+// targetsLabelNames returns names of labels of the targets, labels identifying the target go first and static labels
+// follow them in alphabetical order, static labels missing in some targets are set to empty value in these targets,
+// so metrics of all targets have the same labels
+func targetsLabelNames(targets []*target, identity []string) []string {
+	static := map[string]bool{}
+	for _, t := range targets {
+		for name := range t.labels {
+			static[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(static))
+	for name := range static {
+		if !reservedLabelNames[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, t := range targets {
+		for _, name := range names {
+			if _, ok := t.labels[name]; !ok {
+				t.labels[name] = ""
+			}
+		}
+	}
+
+	return append(append([]string(nil), identity...), names...)
+}
+
+// labelValues returns values of labels of the target in order of names
+func (t *target) labelValues(names []string) []string {
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = t.labels[name]
+	}
+
+	return values
+}
+
+// key returns the key identifying the target by its module, address and static labels
+func (t *target) key() string {
+	names := make([]string, 0, len(t.labels))
+	for name := range t.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	key := []string{t.module, t.raw}
+	for _, name := range names {
+		key = append(key, name, t.labels[name])
+	}
+
+	return strings.Join(key, "\xff")
+}
+
+// String returns the address of target as it was passed
+func (t *target) String() string {
+	return t.raw
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/monitoring-tools/prom-nginx-exporter/common"
 	"github.com/monitoring-tools/prom-nginx-exporter/exporter"
+	"github.com/monitoring-tools/prom-nginx-exporter/relabel"
 	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -20,6 +22,13 @@ var (
 	gitSummary string
 )
 
+const (
+	// probePath is the path of the handler scraping the single target passed in request
+	probePath = "/probe"
+	// reloadPath is the path of the handler reloading configuration on POST request
+	reloadPath = "/-/reload"
+)
+
 var (
 	landingPage = `<html>
 <head>
@@ -28,6 +37,7 @@ var (
 <body>
 <h1>Prom Nginx exporter</h1>
 <p><a href="/metrics">Metrics</a></p>
+<p><a href="/probe?target=http://localhost/status&module=stub">Probe</a></p>
 </body>
 </html>`
 )
@@ -38,65 +48,390 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	registerExporter(config.Namespace, config.NginxUrls, config.NginxPlusUrls)
-	run(config.ListenAddress, config.MetricsPath)
+	reloader := newReloader(config, prometheus.DefaultRegisterer)
+	if err := reloader.reload(); err != nil {
+		log.Fatalln(err)
+	}
+	go reloader.watchSignals()
+
+	run(config.ListenAddress, config.MetricsPath, reloader)
 }
 
 // parseFlag parses config parameters
 func parseFlag() (*common.Config, error) {
 	var (
-		listenAddress *string
-		metricsPath   *string
-		namespace     *string
-		version       *bool
-		nginxUrls     common.ArrFlags
-		nginxPlusUrls common.ArrFlags
+		listenAddress    *string
+		metricsPath      *string
+		namespace        *string
+		version          *bool
+		fileMaxAge       *time.Duration
+		scrapeTimeout    *time.Duration
+		concurrency      *int
+		scrapeInterval   *time.Duration
+		snapshotMaxAge   *time.Duration
+		nginxUrls        common.ArrFlags
+		nginxPlusUrls    common.ArrFlags
+		healthcheckUrls  common.ArrFlags
+		prometheusUrls   common.ArrFlags
+		prometheusPrefix *string
+		jsonUrls         common.ArrFlags
+		jsonMappingFile  *string
+		regexUrls        common.ArrFlags
+		regexMappingFile *string
+		metricsInclude   *string
+		metricsExclude   *string
+		excludedSections common.ArrFlags
+		plusResponses    *string
+		relabelFile      *string
+		naming           *string
+		continuity       *bool
+		plusStrict       *bool
+		configFile       *string
+		probeLocal       *bool
 	)
 
 	listenAddress = flag.String("listen-address", ":9001", "Address on which to expose metrics and web interface.")
 	metricsPath = flag.String("metrics-path", "/metrics", "Path under which to expose metrics.")
 	namespace = flag.String("namespace", "nginx", "The namespace of metrics.")
 	version = flag.Bool("version", false, "The version of the exporter.")
-	flag.Var(&nginxUrls, "nginx-stats-urls", "An array of Nginx status URLs to gather stats.")
-	flag.Var(&nginxPlusUrls, "nginx-plus-stats-urls", "An array of Nginx Plus status URLs to gather stats.")
+	configFile = flag.String("config.file", "", "The YAML file with targets and their settings(type, alias, labels, timeout, auth, TLS), targets of the file are added to targets of flags.")
+	flag.Var(&nginxUrls, "nginx-stats-urls", "An array of Nginx status URLs(or unix:<socket>:<path>, file://<path>) to gather stats.")
+	flag.Var(&nginxPlusUrls, "nginx-plus-stats-urls", "An array of Nginx Plus status URLs(or unix:<socket>:<path>, file://<path>) to gather stats.")
+	flag.Var(&healthcheckUrls, "healthcheck-stats-urls", "An array of lua-resty-upstream-healthcheck status page URLs(or unix:<socket>:<path>, file://<path>) to gather stats.")
+	flag.Var(&prometheusUrls, "prometheus-stats-urls", "An array of URLs(or unix:<socket>:<path>, file://<path>) exposing metrics in Prometheus text format to gather stats.")
+	prometheusPrefix = flag.String("prometheus-prefix", "", "The prefix added to names of metrics gathered from prometheus-stats-urls.")
+	flag.Var(&jsonUrls, "json-stats-urls", "An array of URLs(or unix:<socket>:<path>, file://<path>) with JSON stats to gather stats using json-mapping-file.")
+	jsonMappingFile = flag.String("json-mapping-file", "", "The YAML file with mapping of JSON stats from json-stats-urls to metrics.")
+	flag.Var(&regexUrls, "regex-stats-urls", "An array of URLs(or unix:<socket>:<path>, file://<path>) with text stats to gather stats using regex-mapping-file.")
+	regexMappingFile = flag.String("regex-mapping-file", "", "The YAML file with regular expressions mapping lines of text stats from regex-stats-urls to metrics.")
+	fileMaxAge = flag.Duration("file-max-age", time.Minute, "Maximum age of stats file(file://) after which the target is treated as down, 0 disables the check.")
+	scrapeTimeout = flag.Duration("scrape-timeout", 4*time.Second, "The deadline of scraping single target.")
+	scrapeInterval = flag.Duration("scrape-interval", 0, "The interval of scraping targets in background, metrics endpoint serves the last completed scrape. 0 means scraping on every request of metrics.")
+	snapshotMaxAge = flag.Duration("snapshot-max-age", 0, "Maximum age of metrics of the last successful scrape exposed for failed targets in background mode, 0 means three scrape intervals.")
+	concurrency = flag.Int("scrape-concurrency", 10, "Maximum number of targets scraped in parallel, 0 means all targets at once.")
+	metricsInclude = flag.String("metrics-include", "", "The regular expression matching names of metrics(without namespace) to expose, all metrics are exposed by default.")
+	metricsExclude = flag.String("metrics-exclude", "", "The regular expression matching names of metrics(without namespace) which are not exposed.")
+	flag.Var(&excludedSections, "nginx-plus-exclude-sections", "An array of sections of Nginx Plus stats(processes, connections, ssl, requests, server_zones, upstreams, caches, stream) which are not scraped.")
+	plusResponses = flag.String("nginx-plus-responses", scraper.BothResponses, "The mode of exposing Nginx Plus responses by status code(labelled, suffixed, both).")
+	plusStrict = flag.Bool("nginx-plus-strict", false, "Report fields of Nginx Plus stats unknown for the version of stats as unknown_fields metric and log their JSON paths once.")
+	relabelFile = flag.String("relabel-config-file", "", "The YAML file with relabel_configs applied to every scraped metric.")
+	continuity = flag.Bool("counter-continuity", false, "Keep counters monotonic across detected restarts of Nginx and Nginx Plus by offsetting them with the last values seen before the restart.")
+	probeLocal = flag.Bool("probe-local-targets", false, "Allow probes of stats files(file://) and unix sockets(unix:), only http and https targets are probed by default.")
+	naming = flag.String("naming", exporter.DefaultNaming, "The naming scheme of metrics(default, official), official scheme exposes metrics of Nginx and Nginx Plus like nginx-prometheus-exporter.")
 
+	flag.Usage = usage
 	flag.Parse()
 
+	sources, err := common.SetFlagsFromEnv(flag.CommandLine, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
 	if *version {
 		fmt.Println(gitSummary)
 		os.Exit(0)
 	}
 
-	if len(nginxUrls) == 0 && len(nginxPlusUrls) == 0 {
-		return nil, errors.New("no nginx or nginx plus stats url specified")
+	if len(jsonUrls) != 0 && *jsonMappingFile == "" {
+		return nil, errors.New("no json mapping file specified for json stats urls")
+	}
+
+	if len(regexUrls) != 0 && *regexMappingFile == "" {
+		return nil, errors.New("no regex mapping file specified for regex stats urls")
+	}
+
+	if !scraper.IsMetricNamePrefix(*prometheusPrefix) {
+		return nil, fmt.Errorf("invalid prefix '%s' of prometheus metrics", *prometheusPrefix)
 	}
 
-	return common.NewConfig(*listenAddress, *metricsPath, *namespace, nginxUrls, nginxPlusUrls), nil
+	for _, section := range excludedSections {
+		if !isNginxPlusSection(section) {
+			return nil, fmt.Errorf("unknown section '%s' of nginx plus stats", section)
+		}
+	}
+
+	if !isResponsesMode(*plusResponses) {
+		return nil, fmt.Errorf("unknown mode '%s' of nginx plus responses", *plusResponses)
+	}
+
+	if !isNaming(*naming) {
+		return nil, fmt.Errorf("unknown naming scheme '%s'", *naming)
+	}
+
+	logFlags(sources)
+
+	return &common.Config{
+		ListenAddress:     *listenAddress,
+		MetricsPath:       *metricsPath,
+		Namespace:         *namespace,
+		NginxUrls:         nginxUrls,
+		NginxPlusUrls:     nginxPlusUrls,
+		HealthcheckUrls:   healthcheckUrls,
+		PrometheusUrls:    prometheusUrls,
+		PrometheusPrefix:  *prometheusPrefix,
+		JSONUrls:          jsonUrls,
+		JSONMappingFile:   *jsonMappingFile,
+		RegexUrls:         regexUrls,
+		RegexMappingFile:  *regexMappingFile,
+		FileMaxAge:        *fileMaxAge,
+		ScrapeTimeout:     *scrapeTimeout,
+		ScrapeConcurrency: *concurrency,
+		ScrapeInterval:    *scrapeInterval,
+		SnapshotMaxAge:    *snapshotMaxAge,
+		MetricsInclude:    *metricsInclude,
+		MetricsExclude:    *metricsExclude,
+		ExcludedSections:  excludedSections,
+		PlusResponses:     *plusResponses,
+		RelabelConfigFile: *relabelFile,
+		Naming:            *naming,
+		CounterContinuity: *continuity,
+		PlusStrict:        *plusStrict,
+		ConfigFile:        *configFile,
+		ProbeLocalTargets: *probeLocal,
+	}, nil
 }
 
-// registerExporter registers custom nginx metrics exporter
-func registerExporter(namespace string, nginxUrls []string, nginxPlusUrls []string) {
+// usage prints usage of flags and names of environment variables setting them
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(
+		os.Stderr,
+		"\nEvery flag can be set by environment variable %s<FLAG>(e.g. %s), values of array flags are separated by comma. Flags take precedence over environment variables.\n",
+		common.EnvPrefix,
+		common.EnvName("nginx-plus-stats-urls"),
+	)
+}
+
+// logFlags logs the effective value and the source of every flag, passwords of urls are redacted
+func logFlags(sources map[string]string) {
+	flag.VisitAll(func(f *flag.Flag) {
+		log.Infof("flag %s=%s (%s)", f.Name, common.RedactURLs(f.Value.String()), sources[f.Name])
+	})
+}
+
+// isNginxPlusSection checks whether the section of nginx plus stats exists
+func isNginxPlusSection(section string) bool {
+	for _, s := range scraper.NginxPlusSections {
+		if s == section {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isResponsesMode checks whether the mode of exposing nginx plus responses is supported
+func isResponsesMode(mode string) bool {
+	for _, m := range scraper.ResponsesModes {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNaming checks whether the naming scheme of metrics is supported
+func isNaming(naming string) bool {
+	for _, n := range exporter.Namings {
+		if n == naming {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newInstance creates nginx metrics exporter and the probe handler of the config, mapping, relabel and config files
+// are read on every call, so the instance reflects their current content, the exporter of the instance isn't started
+func newInstance(config *common.Config) (*instance, error) {
 	var (
 		transport = &http.Transport{ResponseHeaderTimeout: time.Duration(3 * time.Second)}
-		client    = &http.Client{Transport: transport, Timeout: time.Duration(4 * time.Second)}
+		client    = &http.Client{Transport: transport, Timeout: config.ScrapeTimeout}
+		options   = exporter.Options{
+			FileMaxAge:        config.FileMaxAge,
+			Concurrency:       config.ScrapeConcurrency,
+			ScrapeTimeout:     config.ScrapeTimeout,
+			ScrapeInterval:    config.ScrapeInterval,
+			SnapshotMaxAge:    config.SnapshotMaxAge,
+			Naming:            config.Naming,
+			CounterContinuity: config.CounterContinuity,
+			ProbeLocalTargets: config.ProbeLocalTargets,
+		}
+		err error
+	)
+
+	if options.MetricsInclude, err = compileFilter(config.MetricsInclude); err != nil {
+		return nil, fmt.Errorf("invalid metrics include filter: %s", err)
+	}
+
+	if options.MetricsExclude, err = compileFilter(config.MetricsExclude); err != nil {
+		return nil, fmt.Errorf("invalid metrics exclude filter: %s", err)
+	}
+
+	if config.RelabelConfigFile != "" {
+		if options.RelabelConfigs, err = relabel.LoadConfigs(config.RelabelConfigFile); err != nil {
+			return nil, err
+		}
+	}
+
+	modules, err := newModules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ConfigFile != "" {
+		fileConfig, err := common.LoadConfigFile(config.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := addFileTargets(modules, fileConfig, client); err != nil {
+			return nil, fmt.Errorf("invalid config file '%s': %s", config.ConfigFile, err)
+		}
+	}
+
+	exp, err := exporter.NewExporter(client, config.Namespace, modules, options)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(exp); err != nil {
+		return nil, err
+	}
+	return &instance{
+		registry: registry,
+		probe:    exporter.NewProbeHandler(client, config.Namespace, modules, options),
+		exporter: exp,
+	}, nil
+}
+
+// newModules creates modules with their stats urls, json and regex modules are available only with the mapping file
+func newModules(config *common.Config) ([]exporter.Module, error) {
+	nginxPlusScraper := scraper.NewNginxPlusScraper(
+		scraper.WithResponses(config.PlusResponses),
+		scraper.WithStrict(config.PlusStrict),
+		scraper.WithExcludedSections(config.ExcludedSections...),
 	)
 
-	prometheus.MustRegister(exporter.NewNginxPlusExporter(
-		client,
-		scraper.NewNginxScraper(),
-		scraper.NewNginxPlusScraper(),
-		namespace,
-		nginxUrls,
-		nginxPlusUrls,
-	))
+	modules := []exporter.Module{
+		{Name: exporter.NginxModule, Scraper: scraper.NewNginxScraper(), Urls: config.NginxUrls},
+		{Name: exporter.NginxPlusModule, Scraper: nginxPlusScraper, Urls: config.NginxPlusUrls},
+		{Name: exporter.HealthcheckModule, Scraper: scraper.NewHealthcheckScraper(), Urls: config.HealthcheckUrls},
+		{Name: exporter.PrometheusModule, Scraper: scraper.NewPrometheusScraper(config.PrometheusPrefix), Urls: config.PrometheusUrls},
+	}
+
+	if config.JSONMappingFile != "" {
+		jsonMapping, err := scraper.LoadJSONMapping(config.JSONMappingFile)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, exporter.Module{Name: exporter.JSONModule, Scraper: scraper.NewJSONScraper(jsonMapping), Urls: config.JSONUrls})
+	}
+
+	if config.RegexMappingFile != "" {
+		regexMapping, err := scraper.LoadRegexMapping(config.RegexMappingFile)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, exporter.Module{Name: exporter.RegexModule, Scraper: scraper.NewRegexScraper(regexMapping), Urls: config.RegexUrls})
+	}
+
+	return modules, nil
+}
+
+// addFileTargets adds targets of the config file to modules of their types, targets with their own timeout
+// or TLS config get dedicated clients
+func addFileTargets(modules []exporter.Module, config *common.FileConfig, client *http.Client) error {
+	for _, t := range config.Targets {
+		module := findModule(modules, exporter.ResolveModule(t.Type))
+		if module == nil {
+			return fmt.Errorf("unknown type '%s' of target '%s'", t.Type, t.URL)
+		}
+
+		headers, err := t.Headers()
+		if err != nil {
+			return err
+		}
+
+		targetClient, err := newTargetClient(t, client)
+		if err != nil {
+			return err
+		}
+
+		module.Targets = append(module.Targets, exporter.Target{
+			URL:           t.URL,
+			Alias:         t.Alias,
+			Labels:        t.Labels,
+			Headers:       headers,
+			Client:        targetClient,
+			ScrapeTimeout: t.ScrapeTimeout,
+		})
+	}
+
+	return nil
+}
+
+// findModule returns the module of the name, json and regex modules exist only with the mapping file
+func findModule(modules []exporter.Module, name string) *exporter.Module {
+	for i := range modules {
+		if modules[i].Name == name {
+			return &modules[i]
+		}
+	}
+
+	return nil
+}
+
+// newTargetClient creates the client of the target with its own timeout and TLS config, nil means the client
+// of the exporter
+func newTargetClient(config common.TargetConfig, client *http.Client) (*http.Client, error) {
+	if config.ScrapeTimeout == 0 && config.TLSConfig == nil {
+		return nil, nil
+	}
+
+	var (
+		transport = &http.Transport{}
+		timeout   = client.Timeout
+	)
+
+	if base, ok := client.Transport.(*http.Transport); ok {
+		transport.ResponseHeaderTimeout = base.ResponseHeaderTimeout
+	}
+
+	if config.ScrapeTimeout > 0 {
+		transport.ResponseHeaderTimeout, timeout = config.ScrapeTimeout, config.ScrapeTimeout
+	}
+
+	if config.TLSConfig != nil {
+		tlsConfig, err := config.TLSConfig.Build()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// compileFilter compiles the regular expression matching whole name of metric, the empty expression means no filter
+func compileFilter(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile("^(?:" + expr + ")$")
 }
 
-// run runs exporter
-func run(listenAddress, metricsPath string) {
+// run runs exporter serving metrics and probes of the current instance of the reloader
+func run(listenAddress, metricsPath string, reloader *reloader) {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(landingPage))
 	})
-	http.Handle(metricsPath, promhttp.Handler())
+	http.Handle(metricsPath, promhttp.HandlerFor(reloader, promhttp.HandlerOpts{}))
+	http.HandleFunc(probePath, reloader.serveProbe)
+	http.HandleFunc(reloadPath, reloader.serveReload)
 
 	log.Fatal(http.ListenAndServe(listenAddress, nil))
 }
@@ -1,13 +1,45 @@
 package metric
 
+// Type is the type of metric value
+type Type int
+
+const (
+	// Gauge is the type of metric value which can go up and down
+	Gauge Type = iota
+	// Counter is the type of metric value which only increases or resets
+	Counter
+)
+
 // Metric is internal struct for operating metric values within the exporter
 type Metric struct {
 	Name   string
-	Value  interface{}
+	Value  float64
 	Labels map[string]string
+	Type   Type
 }
 
 // NewMetric creates new internal metric struct
-func NewMetric(name string, value interface{}, tags map[string]string) Metric {
+func NewMetric(name string, value float64, tags map[string]string) Metric {
 	return Metric{Name: name, Value: value, Labels: tags}
 }
+
+// NewCounter creates new internal metric struct of counter type
+func NewCounter(name string, value float64, tags map[string]string) Metric {
+	return Metric{Name: name, Value: value, Labels: tags, Type: Counter}
+}
+
+// Family describes metrics of the same name: their type, help text and names of labels added by the scraper
+type Family struct {
+	Name   string
+	Type   Type
+	Help   string
+	Labels []string
+}
+
+// LabelNames returns names of labels of the family prepended by names of labels common for all metrics
+func (f Family) LabelNames(common []string) []string {
+	names := make([]string, 0, len(common)+len(f.Labels))
+	names = append(names, common...)
+
+	return append(names, f.Labels...)
+}
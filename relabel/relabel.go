@@ -0,0 +1,273 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Replace sets the target label to the replacement expanded by the regex match of source labels
+	Replace = "replace"
+	// Keep drops metrics whose source labels don't match the regex
+	Keep = "keep"
+	// Drop drops metrics whose source labels match the regex
+	Drop = "drop"
+	// HashMod sets the target label to the modulus of the hash of source labels
+	HashMod = "hashmod"
+	// LabelDrop drops labels whose names match the regex
+	LabelDrop = "labeldrop"
+	// LabelMap copies labels whose names match the regex to labels named by the replacement
+	LabelMap = "labelmap"
+
+	// MetricNameLabel is the pseudo label referring to the name of metric
+	MetricNameLabel = "__name__"
+)
+
+var (
+	// labelNameRegex matches valid names of labels
+	labelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// metricNameRegex matches valid names of metrics
+	metricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+)
+
+// Configs is the file of relabel rules
+type Configs struct {
+	RelabelConfigs []*Config `yaml:"relabel_configs"`
+}
+
+// Config is the relabel rule in format of relabel_configs of Prometheus, example:
+//
+//	source_labels: [upstream]
+//	regex: '(.+)-backend'
+//	target_label: service
+//	replacement: $1
+//
+// The rule is applied to labels of metric, the name of metric is available as label __name__.
+type Config struct {
+	SourceLabels []string `yaml:"source_labels"`
+	Separator    *string  `yaml:"separator"`
+	Regex        *string  `yaml:"regex"`
+	Modulus      uint64   `yaml:"modulus"`
+	TargetLabel  string   `yaml:"target_label"`
+	Replacement  *string  `yaml:"replacement"`
+	Action       string   `yaml:"action"`
+
+	separator   string
+	replacement string
+	regex       *regexp.Regexp
+}
+
+// LoadConfigs loads relabel rules from YAML file with the list relabel_configs
+func LoadConfigs(filename string) ([]*Config, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading relabel file '%s': %s", filename, err)
+	}
+
+	configs := &Configs{}
+	if err := yaml.UnmarshalStrict(content, configs); err != nil {
+		return nil, fmt.Errorf("error parsing relabel file '%s': %s", filename, err)
+	}
+
+	if err := Compile(configs.RelabelConfigs); err != nil {
+		return nil, fmt.Errorf("invalid relabel file '%s': %s", filename, err)
+	}
+
+	return configs.RelabelConfigs, nil
+}
+
+// Compile validates relabel rules and compiles their regular expressions
+func Compile(configs []*Config) error {
+	for i, c := range configs {
+		if err := c.compile(); err != nil {
+			return fmt.Errorf("relabel rule %d: %s", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// compile validates the rule, sets defaults and compiles its regular expression
+func (c *Config) compile() error {
+	if c.Action == "" {
+		c.Action = Replace
+	}
+
+	c.separator = ";"
+	if c.Separator != nil {
+		c.separator = *c.Separator
+	}
+
+	c.replacement = "$1"
+	if c.Replacement != nil {
+		c.replacement = *c.Replacement
+	}
+
+	regex := "(.*)"
+	if c.Regex != nil {
+		regex = *c.Regex
+	}
+
+	var err error
+	if c.regex, err = regexp.Compile("^(?:" + regex + ")$"); err != nil {
+		return fmt.Errorf("invalid regex '%s': %s", regex, err)
+	}
+
+	// replacements without references to groups are checked here, others are checked on processing
+	literal := !strings.Contains(c.replacement, "$")
+
+	switch c.Action {
+	case Replace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action '%s'", c.Action)
+		}
+		if c.TargetLabel == MetricNameLabel && literal && c.replacement != "" && !metricNameRegex.MatchString(c.replacement) {
+			return fmt.Errorf("invalid metric name '%s' of replacement", c.replacement)
+		}
+	case HashMod:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action '%s'", c.Action)
+		}
+		if c.Modulus == 0 {
+			return fmt.Errorf("modulus is required for action '%s'", c.Action)
+		}
+	case Keep, Drop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("source_labels are required for action '%s'", c.Action)
+		}
+	case LabelMap:
+		if literal && !labelNameRegex.MatchString(c.replacement) {
+			return fmt.Errorf("invalid label name '%s' of replacement", c.replacement)
+		}
+	case LabelDrop:
+	default:
+		return fmt.Errorf("unknown action '%s'", c.Action)
+	}
+
+	return nil
+}
+
+// Process applies relabel rules to the metric in order, false is returned if the metric is dropped.
+// The metric whose name or names of labels produced by rules are invalid is dropped with the error.
+// Labels of the passed metric are never modified, they are copied on first change
+func Process(m metric.Metric, configs []*Config) (metric.Metric, bool, error) {
+	copied := false
+	set := func(name string, value string) error {
+		if name == MetricNameLabel {
+			if value != "" {
+				if !metricNameRegex.MatchString(value) {
+					return fmt.Errorf("invalid metric name '%s' produced from '%s'", value, m.Name)
+				}
+				m.Name = value
+			}
+			return nil
+		}
+
+		if !copied {
+			m.Labels = copyLabels(m.Labels)
+			copied = true
+		}
+
+		if value == "" {
+			delete(m.Labels, name)
+		} else {
+			m.Labels[name] = value
+		}
+		return nil
+	}
+
+	for _, c := range configs {
+		switch c.Action {
+		case Drop:
+			if c.regex.MatchString(sourceValue(m, c)) {
+				return m, false, nil
+			}
+		case Keep:
+			if !c.regex.MatchString(sourceValue(m, c)) {
+				return m, false, nil
+			}
+		case Replace:
+			value := sourceValue(m, c)
+			match := c.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+
+			target := string(c.regex.ExpandString(nil, c.TargetLabel, value, match))
+			if target != MetricNameLabel && !labelNameRegex.MatchString(target) {
+				continue
+			}
+			if err := set(target, string(c.regex.ExpandString(nil, c.replacement, value, match))); err != nil {
+				return m, false, err
+			}
+		case HashMod:
+			sum := md5.Sum([]byte(sourceValue(m, c)))
+			if err := set(c.TargetLabel, fmt.Sprint(sum64(sum)%c.Modulus)); err != nil {
+				return m, false, err
+			}
+		case LabelDrop:
+			for name := range m.Labels {
+				if c.regex.MatchString(name) {
+					set(name, "")
+				}
+			}
+		case LabelMap:
+			mapped := map[string]string{}
+			for name, value := range m.Labels {
+				if c.regex.MatchString(name) {
+					target := c.regex.ReplaceAllString(name, c.replacement)
+					if !labelNameRegex.MatchString(target) {
+						return m, false, fmt.Errorf("invalid label name '%s' mapped from '%s' of metric '%s'", target, name, m.Name)
+					}
+					mapped[target] = value
+				}
+			}
+			for name, value := range mapped {
+				set(name, value)
+			}
+		}
+	}
+
+	return m, true, nil
+}
+
+// sourceValue returns values of source labels of the rule joined by the separator
+func sourceValue(m metric.Metric, c *Config) string {
+	values := make([]string, len(c.SourceLabels))
+	for i, name := range c.SourceLabels {
+		if name == MetricNameLabel {
+			values[i] = m.Name
+			continue
+		}
+		values[i] = m.Labels[name]
+	}
+
+	return strings.Join(values, c.separator)
+}
+
+// copyLabels returns copy of labels
+func copyLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for name, value := range labels {
+		result[name] = value
+	}
+
+	return result
+}
+
+// sum64 returns the last 8 bytes of md5 hash as number, the same way as Prometheus does
+func sum64(hash [md5.Size]byte) uint64 {
+	var s uint64
+	for i, b := range hash {
+		shift := uint64((md5.Size - i - 1) * 8)
+		s |= uint64(b) << shift
+	}
+
+	return s
+}
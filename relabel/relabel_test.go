@@ -0,0 +1,146 @@
+package relabel_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/relabel"
+	. "gopkg.in/check.v1"
+)
+
+func TestRelabel(t *testing.T) { TestingT(t) }
+
+type RelabelSuite struct{}
+
+var _ = Suite(&RelabelSuite{})
+
+var validRelabelConfigs = `
+relabel_configs:
+  - source_labels: [__name__, upstream]
+    regex: 'upstream_peer_.+;noisy'
+    action: drop
+  - source_labels: [upstream]
+    regex: '(.+)-backend'
+    target_label: service
+  - source_labels: [zone]
+    regex: '(.+)\.example\.com'
+    target_label: zone
+    replacement: $1
+  - regex: 'server(Address)'
+    replacement: 'nginx$1'
+    action: labelmap
+  - regex: 'server.*|port'
+    action: labeldrop
+  - source_labels: [nginxAddress]
+    target_label: shard
+    modulus: 4
+    action: hashmod
+`
+
+func loadRelabelConfigs(c *C, content string) ([]*relabel.Config, error) {
+	filename := filepath.Join(c.MkDir(), "relabel.yml")
+	c.Assert(ioutil.WriteFile(filename, []byte(content), 0644), IsNil, Commentf("unable to write relabel file"))
+
+	return relabel.LoadConfigs(filename)
+}
+
+func (s RelabelSuite) TestProcess_Success(c *C) {
+	configs, err := loadRelabelConfigs(c, validRelabelConfigs)
+	c.Assert(err, IsNil, Commentf("error occurred during loading relabel configs"))
+
+	labels := map[string]string{"server": "localhost", "port": "80", "upstream": "api-backend", "serverAddress": "10.0.0.1:80"}
+	m, ok, err := relabel.Process(metric.NewMetric("upstream_peer_requests", 1, labels), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, true, Commentf("metric shouldn't be dropped"))
+	c.Assert(m.Name, Equals, "upstream_peer_requests", Commentf("incorrect name of metric"))
+	c.Assert(m.Labels["service"], Equals, "api", Commentf("incorrect value of replaced label"))
+	c.Assert(m.Labels["nginxAddress"], Equals, "10.0.0.1:80", Commentf("incorrect value of mapped label"))
+	c.Assert(m.Labels["shard"], Matches, "[0-3]", Commentf("incorrect value of hashmod label"))
+	c.Assert(len(m.Labels), Equals, 4, Commentf("incorrect set of labels: %v", m.Labels))
+	c.Assert(len(labels), Equals, 4, Commentf("labels of passed metric are modified"))
+
+	m, ok, err = relabel.Process(metric.NewMetric("zone_requests", 1, map[string]string{"zone": "www.example.com"}), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, true, Commentf("metric shouldn't be dropped"))
+	c.Assert(m.Labels["zone"], Equals, "www", Commentf("incorrect value of replaced label"))
+
+	_, ok, err = relabel.Process(metric.NewMetric("upstream_peer_requests", 1, map[string]string{"upstream": "noisy"}), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, false, Commentf("metric should be dropped"))
+
+	_, ok, err = relabel.Process(metric.NewMetric("upstream_keepalive", 1, map[string]string{"upstream": "noisy"}), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, true, Commentf("metric shouldn't be dropped"))
+}
+
+func (s RelabelSuite) TestProcessKeep_Success(c *C) {
+	configs, err := loadRelabelConfigs(c, `
+relabel_configs:
+  - source_labels: [__name__]
+    regex: 'zone_.*'
+    action: keep
+  - source_labels: [__name__]
+    regex: 'zone_(.*)'
+    target_label: __name__
+    replacement: server_zone_$1
+`)
+	c.Assert(err, IsNil, Commentf("error occurred during loading relabel configs"))
+
+	m, ok, err := relabel.Process(metric.NewMetric("zone_requests", 1, nil), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, true, Commentf("metric shouldn't be dropped"))
+	c.Assert(m.Name, Equals, "server_zone_requests", Commentf("incorrect name of renamed metric"))
+
+	_, ok, err = relabel.Process(metric.NewMetric("upstream_keepalive", 1, nil), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, false, Commentf("metric should be dropped"))
+}
+
+func (s RelabelSuite) TestLoadConfigs_Fail(c *C) {
+	invalid := map[string]string{
+		"unknown action":      "relabel_configs:\n  - action: unknown\n",
+		"missing target":      "relabel_configs:\n  - source_labels: [zone]\n",
+		"missing modulus":     "relabel_configs:\n  - source_labels: [zone]\n    target_label: shard\n    action: hashmod\n",
+		"invalid regex":       "relabel_configs:\n  - regex: '('\n    action: labeldrop\n",
+		"unknown field":       "relabel_configs:\n  - regexp: 'zone'\n    action: labeldrop\n",
+		"keep without source": "relabel_configs:\n  - regex: 'zone'\n    action: keep\n",
+		"invalid name":        "relabel_configs:\n  - target_label: __name__\n    replacement: 'zone-requests'\n",
+		"invalid mapped name": "relabel_configs:\n  - regex: 'server'\n    replacement: 'nginx-server'\n    action: labelmap\n",
+	}
+
+	for name, content := range invalid {
+		_, err := loadRelabelConfigs(c, content)
+		c.Assert(err, NotNil, Commentf("error should be occurred for %s", name))
+	}
+}
+
+func (s RelabelSuite) TestProcessInvalid_Fail(c *C) {
+	configs, err := loadRelabelConfigs(c, `
+relabel_configs:
+  - source_labels: [zone]
+    regex: '(.+)'
+    target_label: __name__
+    replacement: zone_$1
+  - regex: 'upstream_(.+)'
+    replacement: '$1'
+    action: labelmap
+`)
+	c.Assert(err, IsNil, Commentf("error occurred during loading relabel configs"))
+
+	m, ok, err := relabel.Process(metric.NewMetric("requests", 1, map[string]string{"zone": "www"}), configs)
+	c.Assert(err, IsNil, Commentf("error occurred during relabeling"))
+	c.Assert(ok, Equals, true, Commentf("metric shouldn't be dropped"))
+	c.Assert(m.Name, Equals, "zone_www", Commentf("incorrect name of renamed metric"))
+
+	_, ok, err = relabel.Process(metric.NewMetric("requests", 1, map[string]string{"zone": "www.example.com"}), configs)
+	c.Assert(ok, Equals, false, Commentf("metric with invalid name should be dropped"))
+	c.Assert(err, NotNil, Commentf("should be error of invalid metric name"))
+	c.Assert(err.Error(), Equals, "invalid metric name 'zone_www.example.com' produced from 'requests'", Commentf("incorrect error message"))
+
+	_, ok, err = relabel.Process(metric.NewMetric("requests", 1, map[string]string{"upstream_1st": "api"}), configs)
+	c.Assert(ok, Equals, false, Commentf("metric with invalid label name should be dropped"))
+	c.Assert(err, NotNil, Commentf("should be error of invalid label name"))
+	c.Assert(err.Error(), Equals, "invalid label name '1st' mapped from 'upstream_1st' of metric 'requests'", Commentf("incorrect error message"))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/common"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/log"
+)
+
+// instance is the exporter of targets of the config registered in its own registry and the probe handler
+type instance struct {
+	registry *prometheus.Registry
+	probe    http.Handler
+	exporter instanceExporter
+}
+
+// instanceExporter is the exporter of the instance which scrapes targets in background once started
+// and takes over the state of targets of the exporter of the replaced instance
+type instanceExporter interface {
+	prometheus.Collector
+	Inherit(previous prometheus.Collector)
+	Start()
+	Stop()
+}
+
+// reloader serves metrics and probes of the current instance and replaces the instance on reload,
+// the current instance is kept when the new config is invalid
+type reloader struct {
+	config      *common.Config
+	current     *instance
+	success     prometheus.Gauge
+	successTime prometheus.Gauge
+
+	reloadMutex sync.Mutex
+	sync.RWMutex
+}
+
+// newReloader creates reloader of the config, the state of reloads is registered by the registerer
+func newReloader(config *common.Config, registerer prometheus.Registerer) *reloader {
+	r := &reloader{
+		config: config,
+		success: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Name:      "config_last_reload_successful",
+			Help:      "Whether the last configuration reload attempt was successful.",
+		}),
+		successTime: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Name:      "config_last_reload_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful configuration reload.",
+		}),
+	}
+
+	registerer.MustRegister(r.success, r.successTime)
+
+	return r
+}
+
+// reload creates the instance of the config and swaps the current instance with it, the new instance takes over
+// the state of targets of the replaced instance, whose background scraping is stopped, and is started before the swap
+func (r *reloader) reload() error {
+	r.reloadMutex.Lock()
+	defer r.reloadMutex.Unlock()
+
+	inst, err := newInstance(r.config)
+	if err != nil {
+		r.success.Set(0)
+		return err
+	}
+
+	previous := r.instance()
+	if previous != nil {
+		previous.exporter.Stop()
+		inst.exporter.Inherit(previous.exporter)
+	}
+	inst.exporter.Start()
+
+	r.Lock()
+	r.current = inst
+	r.Unlock()
+
+	r.success.Set(1)
+	r.successTime.Set(float64(time.Now().UnixNano()) / 1e9)
+
+	return nil
+}
+
+// instance returns the current instance
+func (r *reloader) instance() *instance {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.current
+}
+
+// Gather gathers metrics of the default registry and the registry of the current instance
+func (r *reloader) Gather() ([]*dto.MetricFamily, error) {
+	return prometheus.Gatherers{prometheus.DefaultGatherer, r.instance().registry}.Gather()
+}
+
+// serveProbe serves the probe by the handler of the current instance
+func (r *reloader) serveProbe(w http.ResponseWriter, req *http.Request) {
+	r.instance().probe.ServeHTTP(w, req)
+}
+
+// serveReload reloads configuration on POST request, the error of the invalid config is returned in response
+func (r *reloader) serveReload(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.reload(); err != nil {
+		log.Errorf("error reloading config: %s", err)
+		http.Error(w, "error reloading config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Info("config reloaded")
+}
+
+// watchSignals reloads configuration on every SIGHUP
+func (r *reloader) watchSignals() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		if err := r.reload(); err != nil {
+			log.Errorf("error reloading config: %s", err)
+			continue
+		}
+		log.Info("config reloaded")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/common"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "gopkg.in/check.v1"
+)
+
+func TestReloader(t *testing.T) { TestingT(t) }
+
+type ReloaderSuite struct{}
+
+var _ = Suite(&ReloaderSuite{})
+
+var nginxStats = "Active connections: 2\n server accepts handled requests\n8522429 8522429 8641727\nReading: 0 Writing: 1 Waiting: 3"
+
+func (s ReloaderSuite) TestReload_Success(c *C) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nginxStats))
+	}))
+	defer up.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	config := &common.Config{
+		Namespace:      "nginx_test",
+		NginxUrls:      []string{up.URL + "/status#alias=up", down.URL + "/status#alias=down"},
+		ScrapeTimeout:  time.Second,
+		ScrapeInterval: time.Hour,
+		PlusResponses:  "both",
+		Naming:         "default",
+	}
+
+	state := prometheus.NewRegistry()
+	r := newReloader(config, state)
+
+	c.Assert(r.reload(), IsNil, Commentf("error occurred during reload of valid config"))
+	c.Assert(gaugeValue(r.success), Equals, float64(1), Commentf("successful reload isn't reported"))
+	c.Assert(gaugeValue(r.successTime) > 0, Equals, true, Commentf("time of successful reload isn't reported"))
+
+	first := r.instance()
+	c.Assert(metricValue(c, first.registry, "nginx_test_active", "up"), Equals, float64(2), Commentf("metrics of started instance are missing"))
+	c.Assert(metricValue(c, first.registry, "nginx_test_scrape_errors_total", "down"), Equals, float64(1), Commentf("incorrect number of errors"))
+
+	c.Assert(r.reload(), IsNil, Commentf("error occurred during reload of valid config"))
+	second := r.instance()
+	c.Assert(second != first, Equals, true, Commentf("instance isn't swapped on reload"))
+	c.Assert(metricValue(c, second.registry, "nginx_test_active", "up"), Equals, float64(2), Commentf("metrics of reloaded instance are missing"))
+	c.Assert(metricValue(c, second.registry, "nginx_test_scrape_errors_total", "down"), Equals, float64(2), Commentf("errors of replaced instance aren't carried over"))
+
+	successTime := gaugeValue(r.successTime)
+	config.MetricsInclude = "("
+	c.Assert(r.reload(), NotNil, Commentf("invalid config is reloaded"))
+	c.Assert(r.instance() == second, Equals, true, Commentf("instance is replaced by invalid config"))
+	c.Assert(gaugeValue(r.success), Equals, float64(0), Commentf("failed reload isn't reported"))
+	c.Assert(gaugeValue(r.successTime), Equals, successTime, Commentf("time of successful reload is changed by failed reload"))
+	c.Assert(metricValue(c, second.registry, "nginx_test_active", "up"), Equals, float64(2), Commentf("metrics of kept instance are missing"))
+}
+
+// gaugeValue returns the current value of the gauge
+func gaugeValue(g prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	g.Write(m)
+
+	return m.GetGauge().GetValue()
+}
+
+// metricValue returns the sum of values of series of the family labelled by the server
+func metricValue(c *C, gatherer prometheus.Gatherer, name, server string) float64 {
+	families, err := gatherer.Gather()
+	c.Assert(err, IsNil, Commentf("error occurred during gather of metrics"))
+
+	value := 0.0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.Metric {
+			for _, label := range m.Label {
+				if label.GetName() == "server" && label.GetValue() == server {
+					value += m.GetGauge().GetValue() + m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return value
+}
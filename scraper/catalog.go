@@ -0,0 +1,203 @@
+package scraper
+
+import "github.com/monitoring-tools/prom-nginx-exporter/metric"
+
+// Describer is implemented by scrapers producing the fixed set of metric families,
+// families of scrapers driven by the scraped content(e.g. prometheus, json, regex) are not known in advance
+type Describer interface {
+	Families() []metric.Family
+}
+
+// ResetDetector is implemented by scrapers able to detect resets of all counters of the target(e.g. restart of nginx)
+// by values of some of scraped metrics
+type ResetDetector interface {
+	// ResetMetrics returns names of metrics used to detect resets
+	ResetMetrics() []string
+	// Reset checks whether counters were reset between scrapes by values of reset metrics in order of their names
+	Reset(previous, current []float64) bool
+}
+
+var (
+	// zoneLabelNames are labels of metrics of server zones
+	zoneLabelNames = []string{"zone"}
+	// zoneCodeLabelNames are labels of responses of server zones by status code
+	zoneCodeLabelNames = []string{"zone", "code"}
+	// upstreamLabelNames are labels of metrics of upstreams
+	upstreamLabelNames = []string{"upstream"}
+	// upstreamPeerLabelNames are labels of metrics of upstream peers
+	upstreamPeerLabelNames = []string{"upstream", "serverAddress"}
+	// upstreamPeerCodeLabelNames are labels of responses of upstream peers by status code
+	upstreamPeerCodeLabelNames = []string{"upstream", "serverAddress", "code"}
+	// cacheLabelNames are labels of metrics of caches
+	cacheLabelNames = []string{"cache"}
+)
+
+// nginxFamilies is the catalog of metrics of ngx_http_stub_status_module
+var nginxFamilies = []metric.Family{
+	gauge("active", "The current number of active client connections including waiting connections."),
+	counter("accepts", "The total number of accepted client connections."),
+	counter("handled", "The total number of handled connections."),
+	counter("requests", "The total number of client requests."),
+	gauge("reading", "The current number of connections where nginx is reading the request header."),
+	gauge("writing", "The current number of connections where nginx is writing the response back to the client."),
+	gauge("waiting", "The current number of idle client connections waiting for a request."),
+}
+
+// healthcheckFamilies is the catalog of metrics of lua-resty-upstream-healthcheck status page
+var healthcheckFamilies = []metric.Family{
+	gauge("upstream_peer_backup", "Whether the upstream peer is the backup server(1) or not(0).", upstreamPeerLabelNames...),
+	gauge("upstream_peer_state", "Whether the upstream peer is up(1) or down(0).", upstreamPeerLabelNames...),
+}
+
+// nginxPlusFamilies is the catalog of metrics of ngx_http_status_module
+var nginxPlusFamilies = []metric.Family{
+	counter("generation", "The total number of configuration reloads."),
+	gauge("load_timestamp", "The time of the last reload of configuration in milliseconds since epoch."),
+
+	counter("processes_respawned", "The total number of abnormally terminated and respawned child processes."),
+
+	counter("connections_accepted", "The total number of accepted client connections."),
+	counter("connections_dropped", "The total number of dropped client connections."),
+	gauge("connections_active", "The current number of active client connections."),
+	gauge("connections_idle", "The current number of idle client connections."),
+
+	counter("ssl_handshakes", "The total number of successful SSL handshakes."),
+	counter("ssl_handshakes_failed", "The total number of failed SSL handshakes."),
+	counter("ssl_session_reuses", "The total number of session reuses during SSL handshake."),
+
+	counter("requests_total", "The total number of client requests."),
+	gauge("requests_current", "The current number of client requests."),
+
+	gauge("zone_processing", "The number of client requests that are currently being processed by the server zone.", zoneLabelNames...),
+	counter("zone_requests", "The total number of client requests received by the server zone.", zoneLabelNames...),
+	counter("zone_received", "The total number of bytes received from clients by the server zone.", zoneLabelNames...),
+	counter("zone_sent", "The total number of bytes sent to clients by the server zone.", zoneLabelNames...),
+	counter("zone_responses", "The total number of responses sent to clients by the server zone by status code.", zoneCodeLabelNames...),
+	counter("zone_responses_1xx", "The total number of responses with 1xx status codes sent by the server zone.", zoneLabelNames...),
+	counter("zone_responses_2xx", "The total number of responses with 2xx status codes sent by the server zone.", zoneLabelNames...),
+	counter("zone_responses_3xx", "The total number of responses with 3xx status codes sent by the server zone.", zoneLabelNames...),
+	counter("zone_responses_4xx", "The total number of responses with 4xx status codes sent by the server zone.", zoneLabelNames...),
+	counter("zone_responses_5xx", "The total number of responses with 5xx status codes sent by the server zone.", zoneLabelNames...),
+	counter("zone_responses_total", "The total number of responses sent to clients by the server zone.", zoneLabelNames...),
+	counter("zone_discarded", "The total number of requests completed without sending a response by the server zone.", zoneLabelNames...),
+
+	gauge("upstream_keepalive", "The current number of idle keepalive connections of the upstream.", upstreamLabelNames...),
+	gauge("upstream_zombies", "The current number of servers removed from the upstream but still processing active requests.", upstreamLabelNames...),
+	gauge("upstream_queue_size", "The current number of requests in the queue of the upstream.", upstreamLabelNames...),
+	gauge("upstream_queue_max_size", "The maximum number of requests that can be in the queue of the upstream.", upstreamLabelNames...),
+	counter("upstream_queue_overflows", "The total number of requests rejected due to the queue overflow of the upstream.", upstreamLabelNames...),
+
+	gauge("upstream_peer_backup", "Whether the upstream peer is the backup server(1) or not(0).", upstreamPeerLabelNames...),
+	gauge("upstream_peer_weight", "The weight of the upstream peer.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_state", "Whether the upstream peer is up(1) or down(0).", upstreamPeerLabelNames...),
+	gauge("upstream_peer_active", "The current number of active connections to the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_requests", "The total number of client requests forwarded to the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_sent", "The total number of bytes sent to the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_received", "The total number of bytes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_fails", "The total number of unsuccessful attempts to communicate with the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_unavail", "The number of times the upstream peer became unavailable for client requests.", upstreamPeerLabelNames...),
+	counter("upstream_peer_healthchecks_checks", "The total number of health check requests made to the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_healthchecks_fails", "The number of failed health checks of the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_healthchecks_unhealthy", "How many times the upstream peer became unhealthy.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_healthchecks_last_passed", "Whether the last health check of the upstream peer passed(1) or failed(0).", upstreamPeerLabelNames...),
+	counter("upstream_peer_downtime", "Total time in milliseconds the upstream peer was unavailable.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_downstart", "The time in milliseconds since epoch when the upstream peer became unavailable.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_selected", "The time in milliseconds since epoch when the upstream peer was last selected to process a request.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_header_time", "The average time in milliseconds to get the response header from the upstream peer.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_response_time", "The average time in milliseconds to get the full response from the upstream peer.", upstreamPeerLabelNames...),
+	gauge("upstream_peer_max_conns", "The limit of active connections to the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses", "The total number of responses received from the upstream peer by status code.", upstreamPeerCodeLabelNames...),
+	counter("upstream_peer_responses_1xx", "The total number of responses with 1xx status codes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses_2xx", "The total number of responses with 2xx status codes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses_3xx", "The total number of responses with 3xx status codes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses_4xx", "The total number of responses with 4xx status codes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses_5xx", "The total number of responses with 5xx status codes received from the upstream peer.", upstreamPeerLabelNames...),
+	counter("upstream_peer_responses_total", "The total number of responses received from the upstream peer.", upstreamPeerLabelNames...),
+
+	gauge("cache_size", "The current size of the cache.", cacheLabelNames...),
+	gauge("cache_max_size", "The limit on the maximum size of the cache.", cacheLabelNames...),
+	gauge("cache_cold", "Whether the cache loader process is still loading data from disk into the cache(1) or not(0).", cacheLabelNames...),
+	counter("cache_hit_responses", "The total number of valid responses read from the cache.", cacheLabelNames...),
+	counter("cache_hit_bytes", "The total number of bytes of valid responses read from the cache.", cacheLabelNames...),
+	counter("cache_stale_responses", "The total number of expired responses read from the cache.", cacheLabelNames...),
+	counter("cache_stale_bytes", "The total number of bytes of expired responses read from the cache.", cacheLabelNames...),
+	counter("cache_updating_responses", "The total number of expired responses read from the cache while responses were being updated.", cacheLabelNames...),
+	counter("cache_updating_bytes", "The total number of bytes of expired responses read from the cache while responses were being updated.", cacheLabelNames...),
+	counter("cache_revalidated_responses", "The total number of expired and revalidated responses read from the cache.", cacheLabelNames...),
+	counter("cache_revalidated_bytes", "The total number of bytes of expired and revalidated responses read from the cache.", cacheLabelNames...),
+	counter("cache_miss_responses", "The total number of responses not found in the cache.", cacheLabelNames...),
+	counter("cache_miss_bytes", "The total number of bytes of responses not found in the cache.", cacheLabelNames...),
+	counter("cache_miss_responses_written", "The total number of responses not found in the cache and written to the cache.", cacheLabelNames...),
+	counter("cache_miss_bytes_written", "The total number of bytes of responses not found in the cache and written to the cache.", cacheLabelNames...),
+	counter("cache_expired_responses", "The total number of expired responses not taken from the cache.", cacheLabelNames...),
+	counter("cache_expired_bytes", "The total number of bytes of expired responses not taken from the cache.", cacheLabelNames...),
+	counter("cache_expired_responses_written", "The total number of expired responses written to the cache.", cacheLabelNames...),
+	counter("cache_expired_bytes_written", "The total number of bytes of expired responses written to the cache.", cacheLabelNames...),
+	counter("cache_responses", "The total number of responses not looked up in the cache.", cacheLabelNames...),
+	counter("cache_bytes", "The total number of bytes of responses not looked up in the cache.", cacheLabelNames...),
+	counter("cache_responses_written", "The total number of responses not looked up in the cache and written to the cache.", cacheLabelNames...),
+	counter("cache_bytes_written", "The total number of bytes of responses not looked up in the cache and written to the cache.", cacheLabelNames...),
+
+	gauge("stream_zone_processing", "The number of client connections that are currently being processed by the stream server zone.", zoneLabelNames...),
+	counter("stream_zone_connections", "The total number of connections accepted by the stream server zone.", zoneLabelNames...),
+	counter("stream_zone_received", "The total number of bytes received from clients by the stream server zone.", zoneLabelNames...),
+	counter("stream_zone_sent", "The total number of bytes sent to clients by the stream server zone.", zoneLabelNames...),
+	counter("stream_zone_sessions", "The total number of sessions of the stream server zone by status code.", zoneCodeLabelNames...),
+	counter("stream_zone_sessions_2xx", "The total number of sessions of the stream server zone completed with 2xx status codes.", zoneLabelNames...),
+	counter("stream_zone_sessions_4xx", "The total number of sessions of the stream server zone completed with 4xx status codes.", zoneLabelNames...),
+	counter("stream_zone_sessions_5xx", "The total number of sessions of the stream server zone completed with 5xx status codes.", zoneLabelNames...),
+	counter("stream_zone_sessions_total", "The total number of completed sessions of the stream server zone.", zoneLabelNames...),
+
+	gauge("stream_upstream_zombies", "The current number of servers removed from the stream upstream but still processing active connections.", upstreamLabelNames...),
+
+	gauge("stream_upstream_peer_backup", "Whether the stream upstream peer is the backup server(1) or not(0).", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_weight", "The weight of the stream upstream peer.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_state", "Whether the stream upstream peer is up(1) or down(0).", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_active", "The current number of connections to the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_connections", "The total number of client connections forwarded to the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_sent", "The total number of bytes sent to the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_received", "The total number of bytes received from the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_fails", "The total number of unsuccessful attempts to communicate with the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_unavail", "The number of times the stream upstream peer became unavailable for client connections.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_healthchecks_checks", "The total number of health check requests made to the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_healthchecks_fails", "The number of failed health checks of the stream upstream peer.", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_healthchecks_unhealthy", "How many times the stream upstream peer became unhealthy.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_healthchecks_last_passed", "Whether the last health check of the stream upstream peer passed(1) or failed(0).", upstreamPeerLabelNames...),
+	counter("stream_upstream_peer_healthchecks_downtime", "Total time in milliseconds the stream upstream peer was unavailable.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_healthchecks_downstart", "The time in milliseconds since epoch when the stream upstream peer became unavailable.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_healthchecks_selected", "The time in milliseconds since epoch when the stream upstream peer was last selected to process a connection.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_connect_time", "The average time in milliseconds to connect to the stream upstream peer.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_first_byte_time", "The average time in milliseconds to receive the first byte of data from the stream upstream peer.", upstreamPeerLabelNames...),
+	gauge("stream_upstream_peer_response_time", "The average time in milliseconds to receive the last byte of data from the stream upstream peer.", upstreamPeerLabelNames...),
+}
+
+// CatalogLabelNames returns names of labels of all catalogs including their names in official naming scheme,
+// so static labels of targets can't clash with them
+func CatalogLabelNames() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, families := range [][]metric.Family{nginxFamilies, healthcheckFamilies, nginxPlusFamilies} {
+		for _, f := range families {
+			for _, name := range f.Labels {
+				for _, n := range []string{name, officialLabelName(name)} {
+					if !seen[n] {
+						seen[n] = true
+						names = append(names, n)
+					}
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// gauge describes the family of gauges
+func gauge(name string, help string, labels ...string) metric.Family {
+	return metric.Family{Name: name, Type: metric.Gauge, Help: help, Labels: labels}
+}
+
+// counter describes the family of counters
+func counter(name string, help string, labels ...string) metric.Family {
+	return metric.Family{Name: name, Type: metric.Counter, Help: help, Labels: labels}
+}
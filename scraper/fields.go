@@ -0,0 +1,132 @@
+package scraper
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// statusType is the type of the root object of nginx plus stats
+var statusType = reflect.TypeOf(Status{})
+
+// streamType is the type of stream section of nginx plus stats
+var streamType = reflect.TypeOf(Stream{})
+
+// jsonField returns the type of the field of the struct by its JSON name, the field is found only when it's
+// supported by the version of stats according to "since" and "until" tags, zero version supports all fields
+func jsonField(typ reflect.Type, name string, version int) (reflect.Type, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if strings.Split(field.Tag.Get("json"), ",")[0] != name {
+			continue
+		}
+
+		if version > 0 {
+			if since, err := strconv.Atoi(field.Tag.Get("since")); err == nil && version < since {
+				return nil, false
+			}
+			if until, err := strconv.Atoi(field.Tag.Get("until")); err == nil && version > until {
+				return nil, false
+			}
+		}
+
+		return field.Type, true
+	}
+
+	return nil, false
+}
+
+// unknownFields collects paths of fields of the decoded JSON value which are not declared by the type
+// or not supported by the version of stats, keys of maps are replaced by "*" and indexes of arrays by "[]"
+func unknownFields(path string, value interface{}, typ reflect.Type, version int, paths map[string]bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for key, nested := range v {
+				fieldType, ok := jsonField(typ, key, version)
+				if !ok {
+					paths[path+"."+key] = true
+					continue
+				}
+				unknownFields(path+"."+key, nested, fieldType, version, paths)
+			}
+		case reflect.Map:
+			for _, nested := range v {
+				unknownFields(path+".*", nested, typ.Elem(), version, paths)
+			}
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice {
+			for _, nested := range v {
+				unknownFields(path+"[]", nested, typ.Elem(), version, paths)
+			}
+		}
+	}
+}
+
+// decode decodes the next value of the stream, in strict mode paths of fields of the value unknown
+// for the version of stats are recorded to the partial error
+func (scr *NginxPlusScraper) decode(dec *json.Decoder, path string, v interface{}, version int, partial *PartialError) error {
+	if !scr.strict {
+		return dec.Decode(v)
+	}
+
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	paths := map[string]bool{}
+	unknownFields(path, value, reflect.TypeOf(v), version, paths)
+	partial.unknown(paths)
+
+	return nil
+}
+
+// checkField records the key of the object of the type in strict mode when the key is unknown
+// for the version of stats
+func (scr *NginxPlusScraper) checkField(path string, key string, typ reflect.Type, version int, partial *PartialError) {
+	if !scr.strict {
+		return
+	}
+
+	if _, ok := jsonField(typ, key, version); !ok {
+		if path != "" {
+			key = path + "." + key
+		}
+		partial.unknown(map[string]bool{key: true})
+	}
+}
+
+// unknown records paths of unknown fields keeping them sorted and unique
+func (e *PartialError) unknown(paths map[string]bool) {
+	for _, field := range e.UnknownFields {
+		paths[field] = true
+	}
+
+	if len(paths) == len(e.UnknownFields) {
+		return
+	}
+
+	e.UnknownFields = make([]string, 0, len(paths))
+	for path := range paths {
+		e.UnknownFields = append(e.UnknownFields, path)
+	}
+	sort.Strings(e.UnknownFields)
+}
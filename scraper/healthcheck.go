@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+)
+
+var (
+	// errIncorrectHealthcheckStats describes parse error due to invalid content of stats
+	errIncorrectHealthcheckStats = errors.New("incorrect healthcheck stats")
+)
+
+// HealthcheckScraper is scraper of status page of lua-resty-upstream-healthcheck module
+type HealthcheckScraper struct{}
+
+// NewHealthcheckScraper creates new lua-resty-upstream-healthcheck status page scraper
+func NewHealthcheckScraper() *HealthcheckScraper {
+	return &HealthcheckScraper{}
+}
+
+// Scrape scrapes state of upstream peers from status page in format:
+//
+//	Nginx Worker PID: 1234
+//	Upstream foo.com
+//	    Primary Peers
+//	        127.0.0.1:12354 UP
+//	    Backup Peers
+//	        127.0.0.1:12356 DOWN
+//
+// header lines in "name: value" format before the first upstream are skipped
+func (scr *HealthcheckScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	var (
+		scanner        = bufio.NewScanner(body)
+		upstreamLabels map[string]string
+		backup         *bool
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		data := strings.Fields(line)
+		if len(data) == 0 {
+			continue
+		}
+
+		switch {
+		case upstreamLabels == nil && data[0] != "Upstream" && strings.Contains(line, ": "):
+			// header lines like "Nginx Worker PID: 1234" go before upstreams
+			continue
+		case data[0] == "Upstream" && len(data) >= 2:
+			upstreamLabels = withLabel(labels, "upstream", data[1])
+			backup = nil
+		case len(data) == 2 && data[1] == "Peers":
+			if upstreamLabels == nil {
+				return errIncorrectHealthcheckStats
+			}
+
+			isBackup := data[0] == "Backup"
+			if !isBackup && data[0] != "Primary" {
+				return fmt.Errorf("unknown kind of peers '%s'", data[0])
+			}
+			backup = &isBackup
+		case len(data) >= 2 && backup != nil:
+			peerLabels := withLabel(upstreamLabels, "serverAddress", data[0])
+
+			state := strings.ToLower(data[1])
+			if state != "up" && state != "down" {
+				return fmt.Errorf("unknown state '%s' of peer '%s'", data[1], data[0])
+			}
+
+			metrics <- metric.NewMetric("upstream_peer_backup", boolValue(*backup), peerLabels)
+			metrics <- metric.NewMetric("upstream_peer_state", stateValue(state), peerLabels)
+		default:
+			return errIncorrectHealthcheckStats
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Families returns the catalog of metrics of healthcheck status page
+func (scr *HealthcheckScraper) Families() []metric.Family {
+	return healthcheckFamilies
+}
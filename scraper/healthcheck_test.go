@@ -0,0 +1,98 @@
+package scraper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	. "gopkg.in/check.v1"
+)
+
+func TestHealthcheckScraper(t *testing.T) { TestingT(t) }
+
+type HealthcheckScraperSuite struct{}
+
+var _ = Suite(&HealthcheckScraperSuite{})
+
+var validHealthcheckStats = `Nginx Worker PID: 12345
+Upstream foo.com
+    Primary Peers
+        127.0.0.1:12354 UP
+        127.0.0.1:12355 DOWN
+    Backup Peers
+        127.0.0.1:12356 UP
+
+Upstream bar.com (NO checkers)
+    Primary Peers
+        127.0.0.2:80 UP
+    Backup Peers
+`
+
+// peerLabels takes a label map and adds the upstream and peer labels.
+func peerLabels(labels map[string]string, upstream string, peer string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out["upstream"] = upstream
+	out["serverAddress"] = peer
+	return out
+}
+
+func (s HealthcheckScraperSuite) TestScrape_Success(c *C) {
+	healthcheckScraper := scraper.NewHealthcheckScraper()
+	reader := strings.NewReader(validHealthcheckStats)
+
+	metrics := make(chan metric.Metric, 8)
+	labels := map[string]string{
+		"host": "localhost",
+		"port": "8080",
+	}
+
+	err := healthcheckScraper.Scrape(reader, metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape healthcheck stats"))
+	c.Assert(len(metrics), Equals, 8, Commentf("incorrect number of metrics"))
+
+	expected := []struct {
+		upstream string
+		peer     string
+		backup   float64
+		state    float64
+	}{
+		{"foo.com", "127.0.0.1:12354", 0, 1},
+		{"foo.com", "127.0.0.1:12355", 0, 0},
+		{"foo.com", "127.0.0.1:12356", 1, 1},
+		{"bar.com", "127.0.0.2:80", 0, 1},
+	}
+
+	for _, e := range expected {
+		m := <-metrics
+		c.Assert(m.Name, Equals, "upstream_peer_backup", Commentf("incorrect metrics name of 'upstream_peer_backup' field"))
+		c.Assert(m.Value, Equals, e.backup, Commentf("incorrect value of metric 'upstream_peer_backup'"))
+		c.Assert(m.Labels, DeepEquals, peerLabels(labels, e.upstream, e.peer), Commentf("incorrect set of labels"))
+
+		m = <-metrics
+		c.Assert(m.Name, Equals, "upstream_peer_state", Commentf("incorrect metrics name of 'upstream_peer_state' field"))
+		c.Assert(m.Value, Equals, e.state, Commentf("incorrect value of metric 'upstream_peer_state'"))
+		c.Assert(m.Labels, DeepEquals, peerLabels(labels, e.upstream, e.peer), Commentf("incorrect set of labels"))
+	}
+}
+
+func (s HealthcheckScraperSuite) TestScrape_Fail(c *C) {
+	healthcheckScraper := scraper.NewHealthcheckScraper()
+	metrics := make(chan metric.Metric, 8)
+	labels := make(map[string]string)
+
+	err := healthcheckScraper.Scrape(strings.NewReader("    Primary Peers\n        127.0.0.1:12354 UP\n"), metrics, labels)
+	c.Assert(err, NotNil, Commentf("should be error of missed upstream"))
+	c.Assert(err.Error(), Equals, "incorrect healthcheck stats", Commentf("peers are listed without upstream"))
+
+	err = healthcheckScraper.Scrape(strings.NewReader("Upstream foo.com\n    Primary Peers\n        127.0.0.1:12354 UNKNOWN\n"), metrics, labels)
+	c.Assert(err, NotNil, Commentf("should be error of parsing peer state"))
+	c.Assert(err.Error(), Equals, "unknown state 'UNKNOWN' of peer '127.0.0.1:12354'", Commentf("error occurred during parse peer state"))
+
+	err = healthcheckScraper.Scrape(strings.NewReader("<html><body>Welcome to nginx!</body></html>"), metrics, labels)
+	c.Assert(err, NotNil, Commentf("should be error of parsing non healthcheck page"))
+	c.Assert(err.Error(), Equals, "incorrect healthcheck stats", Commentf("error occurred during parse non healthcheck page"))
+}
@@ -0,0 +1,357 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+)
+
+const (
+	// jsonPathRoot is the optional prefix of JSON path
+	jsonPathRoot = "$"
+	// jsonPathWildcard matches every key of object or every element of array
+	jsonPathWildcard = "*"
+	// jsonCapturePrefix is the prefix of label value referring to the key matched by wildcard(example: $1)
+	jsonCapturePrefix = "$"
+)
+
+// JSONMapping is the mapping of JSON stats to metrics
+type JSONMapping struct {
+	Metrics []*JSONMetric `yaml:"metrics"`
+}
+
+// JSONMetric describes metric taken from JSON stats, example:
+//
+//	name: upstream_peer_state
+//	path: upstreams.*.peers[*]
+//	value: state
+//	type: gauge
+//	labels:
+//	  upstream: $1
+//	  serverAddress: server
+//	value_mapping:
+//	  up: 1
+//	  down: 0
+//
+// The path selects nodes of JSON document, the value is taken from the path relative to the selected node.
+// The label value is either the key matched by wildcard of the path($1 is the first one) or the path
+// relative to the selected node.
+type JSONMetric struct {
+	Name         string             `yaml:"name"`
+	Path         string             `yaml:"path"`
+	Value        string             `yaml:"value"`
+	Type         string             `yaml:"type"`
+	Labels       map[string]string  `yaml:"labels"`
+	ValueMapping map[string]float64 `yaml:"value_mapping"`
+
+	path   jsonPath
+	value  jsonPath
+	labels map[string]jsonLabel
+}
+
+// jsonPath is the compiled JSON path, every step is either the key or the wildcard
+type jsonPath []string
+
+// jsonLabel is the compiled label value of JSON metric
+type jsonLabel struct {
+	capture int
+	path    jsonPath
+}
+
+// LoadJSONMapping loads the mapping of JSON stats to metrics from YAML file
+func LoadJSONMapping(filename string) (*JSONMapping, error) {
+	mapping := &JSONMapping{}
+	if err := loadMappingFile(filename, mapping); err != nil {
+		return nil, fmt.Errorf("error loading JSON mapping: %s", err)
+	}
+
+	return mapping, nil
+}
+
+// compile validates the mapping and compiles paths of metrics
+func (m *JSONMapping) compile() error {
+	if len(m.Metrics) == 0 {
+		return fmt.Errorf("no metrics defined")
+	}
+
+	for _, jm := range m.Metrics {
+		if err := jm.compile(); err != nil {
+			return fmt.Errorf("metric '%s': %s", jm.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// compile validates the metric and compiles its paths
+func (jm *JSONMetric) compile() error {
+	if jm.Name == "" {
+		return fmt.Errorf("empty name")
+	}
+
+	if !metricNameRegexp.MatchString(jm.Name) {
+		return fmt.Errorf("invalid name '%s'", jm.Name)
+	}
+
+	for name := range jm.Labels {
+		if err := checkMappingLabelName(name); err != nil {
+			return err
+		}
+	}
+
+	switch jm.Type {
+	case "":
+		jm.Type = "gauge"
+	case "gauge", "counter":
+	default:
+		return fmt.Errorf("unsupported type '%s'", jm.Type)
+	}
+
+	var err error
+	if jm.path, err = parseJSONPath(jm.Path); err != nil {
+		return err
+	}
+
+	if jm.value, err = parseJSONPath(jm.Value); err != nil {
+		return err
+	}
+	if jm.value.hasWildcard() {
+		return fmt.Errorf("wildcard is not allowed in value path '%s'", jm.Value)
+	}
+
+	wildcards := jm.path.wildcards()
+	jm.labels = make(map[string]jsonLabel, len(jm.Labels))
+	for name, value := range jm.Labels {
+		if strings.HasPrefix(value, jsonCapturePrefix) {
+			capture, err := strconv.Atoi(strings.TrimPrefix(value, jsonCapturePrefix))
+			if err != nil || capture < 1 || capture > wildcards {
+				return fmt.Errorf("label '%s' refers to unknown wildcard '%s'", name, value)
+			}
+			jm.labels[name] = jsonLabel{capture: capture}
+			continue
+		}
+
+		path, err := parseJSONPath(value)
+		if err != nil {
+			return err
+		}
+		if path.hasWildcard() {
+			return fmt.Errorf("wildcard is not allowed in path '%s' of label '%s'", value, name)
+		}
+		jm.labels[name] = jsonLabel{path: path}
+	}
+
+	return nil
+}
+
+// parseJSONPath parses JSON path like "$.upstreams.*.peers[*].state", the empty path selects the node itself
+func parseJSONPath(path string) (jsonPath, error) {
+	steps := jsonPath{}
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			i := strings.Index(part, "[")
+			if i < 0 {
+				steps = append(steps, part)
+				break
+			}
+
+			j := strings.Index(part, "]")
+			if j < i {
+				return nil, fmt.Errorf("incorrect JSON path '%s'", path)
+			}
+
+			if i > 0 {
+				steps = append(steps, part[:i])
+			}
+			steps = append(steps, part[i+1:j])
+			part = part[j+1:]
+		}
+	}
+
+	if len(steps) > 0 && steps[0] == jsonPathRoot {
+		steps = steps[1:]
+	}
+
+	for _, step := range steps {
+		if step == "" {
+			return nil, fmt.Errorf("incorrect JSON path '%s'", path)
+		}
+	}
+
+	return steps, nil
+}
+
+// wildcards returns number of wildcards in the path
+func (p jsonPath) wildcards() int {
+	n := 0
+	for _, step := range p {
+		if step == jsonPathWildcard {
+			n++
+		}
+	}
+
+	return n
+}
+
+// hasWildcard checks whether path contains wildcard
+func (p jsonPath) hasWildcard() bool {
+	return p.wildcards() > 0
+}
+
+// walk calls fn for every node matched by the path, captures are keys matched by wildcards
+func (p jsonPath) walk(node interface{}, captures []string, fn func(node interface{}, captures []string)) {
+	if len(p) == 0 {
+		fn(node, captures)
+		return
+	}
+
+	step, rest := p[0], p[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if step != jsonPathWildcard {
+			if child, ok := n[step]; ok {
+				rest.walk(child, captures, fn)
+			}
+			return
+		}
+
+		keys := make([]string, 0, len(n))
+		for key := range n {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			rest.walk(n[key], append(captures[:len(captures):len(captures)], key), fn)
+		}
+	case []interface{}:
+		if step != jsonPathWildcard {
+			if i, err := strconv.Atoi(step); err == nil && i >= 0 && i < len(n) {
+				rest.walk(n[i], captures, fn)
+			}
+			return
+		}
+
+		for i, child := range n {
+			rest.walk(child, append(captures[:len(captures):len(captures)], strconv.Itoa(i)), fn)
+		}
+	}
+}
+
+// lookup returns the node selected by path without wildcards
+func (p jsonPath) lookup(node interface{}) (interface{}, bool) {
+	var (
+		found  interface{}
+		exists bool
+	)
+
+	p.walk(node, nil, func(n interface{}, _ []string) {
+		found, exists = n, true
+	})
+
+	return found, exists
+}
+
+// JSONScraper is scraper of arbitrary JSON stats driven by the mapping
+type JSONScraper struct {
+	mapping *JSONMapping
+}
+
+// NewJSONScraper creates new JSON stats scraper using the mapping
+func NewJSONScraper(mapping *JSONMapping) *JSONScraper {
+	return &JSONScraper{mapping: mapping}
+}
+
+// Scrape scrapes metrics described by the mapping from JSON stats,
+// the values which can't be converted are skipped and the first conversion error is returned
+func (scr *JSONScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+
+	var document interface{}
+	if err := dec.Decode(&document); err != nil {
+		return fmt.Errorf("error while decoding JSON response")
+	}
+
+	var firstErr error
+	for _, jm := range scr.mapping.Metrics {
+		jm.path.walk(document, nil, func(node interface{}, captures []string) {
+			if err := scr.scrapeMetric(jm, node, captures, metrics, labels); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		})
+	}
+
+	return firstErr
+}
+
+// scrapeMetric scrapes value of the metric from the selected node
+func (scr *JSONScraper) scrapeMetric(
+	jm *JSONMetric,
+	node interface{},
+	captures []string,
+	metrics chan<- metric.Metric,
+	labels map[string]string,
+) error {
+	raw, ok := jm.value.lookup(node)
+	if !ok {
+		return nil
+	}
+
+	value, err := jm.convertValue(raw)
+	if err != nil {
+		return fmt.Errorf("unable to convert value of metric '%s': %s", jm.Name, err)
+	}
+
+	metricLabels := make(map[string]string, len(labels)+len(jm.labels))
+	for k, v := range labels {
+		metricLabels[k] = v
+	}
+
+	for name, label := range jm.labels {
+		if label.capture > 0 {
+			setMappingLabel(metricLabels, labels, name, captures[label.capture-1])
+			continue
+		}
+
+		if n, ok := label.path.lookup(node); ok {
+			setMappingLabel(metricLabels, labels, name, fmt.Sprint(n))
+		} else {
+			setMappingLabel(metricLabels, labels, name, "")
+		}
+	}
+
+	if jm.Type == "counter" {
+		metrics <- metric.NewCounter(jm.Name, value, metricLabels)
+	} else {
+		metrics <- metric.NewMetric(jm.Name, value, metricLabels)
+	}
+
+	return nil
+}
+
+// convertValue converts JSON value to float64, strings are converted using the value mapping
+func (jm *JSONMetric) convertValue(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
+	case json.Number:
+		return v.Float64()
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if mapped, ok := jm.ValueMapping[v]; ok {
+			return mapped, nil
+		}
+		return strconv.ParseFloat(v, 64)
+	}
+
+	return 0, fmt.Errorf("unsupported value '%v'", raw)
+}
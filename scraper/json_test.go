@@ -0,0 +1,167 @@
+package scraper_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	. "gopkg.in/check.v1"
+)
+
+func TestJSONScraper(t *testing.T) { TestingT(t) }
+
+type JSONScraperSuite struct{}
+
+var _ = Suite(&JSONScraperSuite{})
+
+var validJSONMapping = `
+metrics:
+  - name: zone_requests
+    path: $.server_zones.*
+    value: requests
+    type: counter
+    labels:
+      zone: $1
+  - name: upstream_peer_state
+    path: upstreams.*.peers[*]
+    value: state
+    labels:
+      upstream: $1
+      serverAddress: server
+    value_mapping:
+      up: 1
+      down: 0
+  - name: version
+    path: version
+`
+
+var validJSONStats = `{
+    "version": 7,
+    "server_zones": {
+        "zone_b": {"requests": 20},
+        "zone_a": {"requests": 10}
+    },
+    "upstreams": {
+        "backend": {
+            "peers": [
+                {"server": "10.0.0.1:80", "state": "up"},
+                {"server": "10.0.0.2:80", "state": "down"}
+            ]
+        }
+    }
+}`
+
+// loadJSONMapping writes mapping to the temporary file and loads it
+func loadJSONMapping(c *C, content string) (*scraper.JSONMapping, error) {
+	filename := filepath.Join(c.MkDir(), "mapping.yml")
+	c.Assert(ioutil.WriteFile(filename, []byte(content), 0644), IsNil, Commentf("unable to write mapping file"))
+
+	return scraper.LoadJSONMapping(filename)
+}
+
+func (s JSONScraperSuite) TestScrape_Success(c *C) {
+	mapping, err := loadJSONMapping(c, validJSONMapping)
+	c.Assert(err, IsNil, Commentf("error occurred during load of json mapping"))
+
+	jsonScraper := scraper.NewJSONScraper(mapping)
+	metrics := make(chan metric.Metric, 5)
+	labels := map[string]string{"server": "localhost", "port": "8080"}
+
+	err = jsonScraper.Scrape(strings.NewReader(validJSONStats), metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape json stats"))
+	c.Assert(len(metrics), Equals, 5, Commentf("incorrect number of metrics"))
+
+	for _, zone := range []struct {
+		name     string
+		requests float64
+	}{{"zone_a", 10}, {"zone_b", 20}} {
+		m := <-metrics
+		c.Assert(m.Name, Equals, "zone_requests", Commentf("incorrect metrics name of 'zone_requests' field"))
+		c.Assert(m.Type, Equals, metric.Counter, Commentf("incorrect type of metric 'zone_requests'"))
+		c.Assert(m.Value, Equals, zone.requests, Commentf("incorrect value of metric 'zone_requests'"))
+		c.Assert(m.Labels, DeepEquals, map[string]string{"server": "localhost", "port": "8080", "zone": zone.name}, Commentf("incorrect set of labels"))
+	}
+
+	for _, peer := range []struct {
+		server string
+		state  float64
+	}{{"10.0.0.1:80", 1}, {"10.0.0.2:80", 0}} {
+		m := <-metrics
+		c.Assert(m.Name, Equals, "upstream_peer_state", Commentf("incorrect metrics name of 'upstream_peer_state' field"))
+		c.Assert(m.Type, Equals, metric.Gauge, Commentf("incorrect type of metric 'upstream_peer_state'"))
+		c.Assert(m.Value, Equals, peer.state, Commentf("incorrect value of metric 'upstream_peer_state'"))
+		c.Assert(m.Labels, DeepEquals, map[string]string{
+			"server":        "localhost",
+			"port":          "8080",
+			"upstream":      "backend",
+			"serverAddress": peer.server,
+		}, Commentf("incorrect set of labels"))
+	}
+
+	m := <-metrics
+	c.Assert(m.Name, Equals, "version", Commentf("incorrect metrics name of 'version' field"))
+	c.Assert(m.Value, Equals, float64(7), Commentf("incorrect value of metric 'version'"))
+	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
+}
+
+func (s JSONScraperSuite) TestScrape_Fail(c *C) {
+	mapping, err := loadJSONMapping(c, "metrics:\n  - name: upstream_peer_state\n    path: upstreams.*.peers[*].state\n")
+	c.Assert(err, IsNil, Commentf("error occurred during load of json mapping"))
+
+	jsonScraper := scraper.NewJSONScraper(mapping)
+	metrics := make(chan metric.Metric, 5)
+
+	err = jsonScraper.Scrape(strings.NewReader(`{"version":`), metrics, map[string]string{})
+	c.Assert(err, NotNil, Commentf("error should be occurred"))
+	c.Assert(err.Error(), Equals, "error while decoding JSON response", Commentf("incorrect error massage of parsing json"))
+
+	err = jsonScraper.Scrape(strings.NewReader(validJSONStats), metrics, map[string]string{})
+	c.Assert(err, NotNil, Commentf("should be error of converting unmapped string value"))
+	c.Assert(err.Error(), Equals, "unable to convert value of metric 'upstream_peer_state': strconv.ParseFloat: parsing \"up\": invalid syntax", Commentf("incorrect error message"))
+}
+
+func (s JSONScraperSuite) TestLoadMapping_Fail(c *C) {
+	_, err := loadJSONMapping(c, "metrics:\n  - name: zone_requests\n    path: server_zones.*\n    labels:\n      zone: $2\n")
+	c.Assert(err, NotNil, Commentf("should be error of unknown wildcard"))
+	c.Assert(strings.HasSuffix(err.Error(), "metric 'zone_requests': label 'zone' refers to unknown wildcard '$2'"), Equals, true, Commentf("incorrect error message"))
+
+	_, err = loadJSONMapping(c, "metrics:\n  - name: zone_requests\n    path: server_zones.*\n    type: histogram\n")
+	c.Assert(err, NotNil, Commentf("should be error of unsupported type"))
+	c.Assert(strings.HasSuffix(err.Error(), "metric 'zone_requests': unsupported type 'histogram'"), Equals, true, Commentf("incorrect error message"))
+
+	_, err = loadJSONMapping(c, "metrics:\n  - name: zone-requests\n    path: server_zones.*\n")
+	c.Assert(err, NotNil, Commentf("should be error of invalid metric name"))
+	c.Assert(strings.HasSuffix(err.Error(), "metric 'zone-requests': invalid name 'zone-requests'"), Equals, true, Commentf("incorrect error message"))
+
+	_, err = loadJSONMapping(c, "metrics:\n  - name: zone_requests\n    path: server_zones.*\n    labels:\n      zone-name: $1\n")
+	c.Assert(err, NotNil, Commentf("should be error of invalid label name"))
+	c.Assert(strings.HasSuffix(err.Error(), "metric 'zone_requests': invalid label name 'zone-name'"), Equals, true, Commentf("incorrect error message"))
+
+	_, err = loadJSONMapping(c, "metrics:\n  - name: zone_requests\n    path: server_zones.*\n    labels:\n      server: $1\n")
+	c.Assert(err, NotNil, Commentf("should be error of reserved label name"))
+	c.Assert(strings.HasSuffix(err.Error(), "metric 'zone_requests': reserved label name 'server'"), Equals, true, Commentf("incorrect error message"))
+}
+
+func (s JSONScraperSuite) TestScrapeStaticLabels_Success(c *C) {
+	mapping, err := loadJSONMapping(c, "metrics:\n  - name: zone_requests\n    path: server_zones.*\n    value: requests\n    labels:\n      zone: $1\n")
+	c.Assert(err, IsNil, Commentf("error occurred during load of json mapping"))
+
+	jsonScraper := scraper.NewJSONScraper(mapping)
+	metrics := make(chan metric.Metric, 2)
+	labels := map[string]string{"server": "localhost", "port": "8080", "zone": "static"}
+
+	err = jsonScraper.Scrape(strings.NewReader(`{"server_zones": {"zone_a": {"requests": 10}}}`), metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape json stats"))
+	c.Assert(len(metrics), Equals, 1, Commentf("incorrect number of metrics"))
+
+	m := <-metrics
+	c.Assert(m.Labels, DeepEquals, map[string]string{
+		"server":        "localhost",
+		"port":          "8080",
+		"zone":          "static",
+		"exported_zone": "zone_a",
+	}, Commentf("labels of the target should not be overwritten"))
+}
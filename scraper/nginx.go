@@ -19,8 +19,8 @@ var (
 type NginxScraper struct{}
 
 // NewNginxScraper creates new nginx status scraper
-func NewNginxScraper() NginxScraper {
-	return NginxScraper{}
+func NewNginxScraper() *NginxScraper {
+	return &NginxScraper{}
 }
 
 // Scrape scrapes full information from nginx
@@ -63,7 +63,7 @@ func (scr *NginxScraper) scrapeActiveConnections(reader *bufio.Reader, metrics c
 		return err
 	}
 
-	metrics <- metric.NewMetric("active", active, labels)
+	metrics <- metric.NewMetric("active", float64(active), labels)
 
 	return nil
 }
@@ -89,19 +89,19 @@ func (scr *NginxScraper) scrapeAcceptsHandledRequests(reader *bufio.Reader, metr
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("accepts", accepts, labels)
+	metrics <- metric.NewMetric("accepts", float64(accepts), labels)
 
 	handled, err := strconv.ParseUint(data[1], 10, 64)
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("handled", handled, labels)
+	metrics <- metric.NewMetric("handled", float64(handled), labels)
 
 	requests, err := strconv.ParseUint(data[2], 10, 64)
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("requests", requests, labels)
+	metrics <- metric.NewMetric("requests", float64(requests), labels)
 
 	return nil
 }
@@ -122,19 +122,34 @@ func (scr *NginxScraper) scrapeReadingWritingWaiting(reader *bufio.Reader, metri
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("reading", reading, labels)
+	metrics <- metric.NewMetric("reading", float64(reading), labels)
 
 	writing, err := strconv.ParseUint(data[3], 10, 64)
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("writing", writing, labels)
+	metrics <- metric.NewMetric("writing", float64(writing), labels)
 
 	waiting, err := strconv.ParseUint(data[5], 10, 64)
 	if err != nil {
 		return err
 	}
-	metrics <- metric.NewMetric("waiting", waiting, labels)
+	metrics <- metric.NewMetric("waiting", float64(waiting), labels)
 
 	return nil
 }
+
+// ResetMetrics returns names of metrics used to detect resets of counters
+func (scr *NginxScraper) ResetMetrics() []string {
+	return []string{"accepts"}
+}
+
+// Reset detects the restart of nginx by decreased number of accepted connections
+func (scr *NginxScraper) Reset(previous, current []float64) bool {
+	return current[0] < previous[0]
+}
+
+// Families returns the catalog of metrics of nginx stats
+func (scr *NginxScraper) Families() []metric.Family {
+	return nginxFamilies
+}
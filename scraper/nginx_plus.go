@@ -5,271 +5,578 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/monitoring-tools/prom-nginx-exporter/metric"
 )
 
+// NginxPlusSections are sections of nginx plus stats which can be excluded from scraping
+var NginxPlusSections = []string{"processes", "connections", "ssl", "requests", "server_zones", "upstreams", "caches", "stream"}
+
+const (
+	// LabelledResponses exposes responses by status code as the single family labelled by "code"(e.g. zone_responses{code="2xx"})
+	LabelledResponses = "labelled"
+	// SuffixedResponses exposes responses by status code as the family per status code(e.g. zone_responses_2xx)
+	SuffixedResponses = "suffixed"
+	// BothResponses exposes responses by status code both labelled and suffixed
+	BothResponses = "both"
+)
+
+// ResponsesModes are modes of exposing responses of server zones, upstream peers and sessions of stream server zones
+var ResponsesModes = []string{LabelledResponses, SuffixedResponses, BothResponses}
+
 // NginxPlusScraper is scraper for getting nginx plus metrics
-type NginxPlusScraper struct{}
+type NginxPlusScraper struct {
+	excludedSections map[string]bool
+	responses        string
+	states           map[string]float64
+	strict           bool
+}
 
-// NewNginxPlusScraper crates new nginx plus stats scraper
-func NewNginxPlusScraper() NginxPlusScraper {
-	return NginxPlusScraper{}
+// NginxPlusOption is the option of nginx plus stats scraper
+type NginxPlusOption func(scr *NginxPlusScraper)
+
+// WithResponses sets the mode of exposing responses by status code, empty mode means both labelled
+// and suffixed responses
+func WithResponses(mode string) NginxPlusOption {
+	return func(scr *NginxPlusScraper) {
+		if mode != "" {
+			scr.responses = mode
+		}
+	}
 }
 
-// Scrape scrapes stats from nginx plus module
+// WithStrict switches strict mode, where fields of stats unknown for their version are reported as the partial error
+func WithStrict(strict bool) NginxPlusOption {
+	return func(scr *NginxPlusScraper) {
+		scr.strict = strict
+	}
+}
+
+// WithExcludedSections sets sections of stats which are skipped without decoding
+func WithExcludedSections(sections ...string) NginxPlusOption {
+	return func(scr *NginxPlusScraper) {
+		for _, section := range sections {
+			scr.excludedSections[section] = true
+		}
+	}
+}
+
+// NewNginxPlusScraper crates new nginx plus stats scraper, by default both labelled and suffixed responses
+// are exposed, no sections are excluded and strict mode is off
+func NewNginxPlusScraper(options ...NginxPlusOption) *NginxPlusScraper {
+	scr := &NginxPlusScraper{excludedSections: map[string]bool{}, responses: BothResponses}
+	for _, option := range options {
+		option(scr)
+	}
+
+	return scr
+}
+
+// Scrape scrapes stats from nginx plus module, the stats are decoded as a stream of tokens and metrics
+// of every zone, upstream and cache are sent as soon as they are read, so memory doesn't depend on size of stats
 func (scr *NginxPlusScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
-	dec := json.NewDecoder(bufio.NewReader(body))
+	var (
+		dec     = json.NewDecoder(bufio.NewReader(body))
+		status  = &Status{}
+		partial = &PartialError{}
+	)
+
+	err := decodeObject(dec, func(key string) error {
+		if scr.excludedSections[key] {
+			return skipValue(dec)
+		}
+		scr.checkField("", key, statusType, status.Version, partial)
+
+		switch key {
+		case "version":
+			return dec.Decode(&status.Version)
+		case "nginx_version":
+			return dec.Decode(&status.NginxVersion)
+		case "address":
+			return dec.Decode(&status.Address)
+		case "generation":
+			if err := dec.Decode(&status.Generation); err != nil {
+				return err
+			}
+			if status.Generation != nil {
+				metrics <- metric.NewMetric("generation", float64(*status.Generation), labels)
+			}
+		case "load_timestamp":
+			if err := dec.Decode(&status.LoadTimestamp); err != nil {
+				return err
+			}
+			if status.LoadTimestamp != nil {
+				metrics <- metric.NewMetric("load_timestamp", float64(*status.LoadTimestamp), labels)
+			}
+		case "timestamp":
+			return dec.Decode(&status.Timestamp)
+		case "pid":
+			return dec.Decode(&status.Pid)
+		case "processes":
+			processes := &Processes{}
+			if err := scr.decode(dec, key, processes, status.Version, partial); err != nil {
+				return partial.skip(key, err)
+			}
+			scr.scrapeProcesses(processes, metrics, labels)
+		case "connections":
+			connections := Connections{}
+			if err := scr.decode(dec, key, &connections, status.Version, partial); err != nil {
+				return partial.skip(key, err)
+			}
+			scr.scrapeConnections(connections, metrics, labels)
+		case "ssl":
+			ssl := &Ssl{}
+			if err := scr.decode(dec, key, ssl, status.Version, partial); err != nil {
+				return partial.skip(key, err)
+			}
+			scr.scrapeSsl(ssl, metrics, labels)
+		case "requests":
+			requests := Requests{}
+			if err := scr.decode(dec, key, &requests, status.Version, partial); err != nil {
+				return partial.skip(key, err)
+			}
+			scr.scrapeRequest(requests, metrics, labels)
+		case "server_zones":
+			return partial.skip(key, decodeObject(dec, func(zoneName string) error {
+				zone := ServerZone{}
+				if err := scr.decode(dec, key+".*", &zone, status.Version, partial); err != nil {
+					return partial.skip(key, err)
+				}
+				scr.scrapeZone(zoneName, zone, metrics, labels)
+				return nil
+			}))
+		case "upstreams":
+			return partial.skip(key, decodeObject(dec, func(upstreamName string) error {
+				upstream := Upstream{}
+				if err := scr.decode(dec, key+".*", &upstream, status.Version, partial); err != nil {
+					return partial.skip(key, err)
+				}
+				scr.scrapeUpstream(upstreamName, upstream, metrics, labels)
+				return nil
+			}))
+		case "caches":
+			return partial.skip(key, decodeObject(dec, func(cacheName string) error {
+				cache := Cache{}
+				if err := scr.decode(dec, key+".*", &cache, status.Version, partial); err != nil {
+					return partial.skip(key, err)
+				}
+				scr.scrapeCache(cacheName, cache, metrics, labels)
+				return nil
+			}))
+		case "stream":
+			return partial.skip(key, scr.scrapeStream(dec, status.Version, partial, metrics, labels))
+		default:
+			return skipValue(dec)
+		}
 
-	status := &Status{}
-	if err := dec.Decode(status); err != nil {
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("error while decoding JSON response")
 	}
 
-	scr.scrapeProcesses(status, metrics, labels)
-	scr.scrapeConnections(status, metrics, labels)
-	scr.scrapeSsl(status, metrics, labels)
-	scr.scrapeRequest(status, metrics, labels)
-	scr.scrapeZone(status, metrics, labels)
-	scr.scrapeUpstream(status, metrics, labels)
-	scr.scrapeCache(status, metrics, labels)
-	scr.scrapeStream(status, metrics, labels)
+	if len(partial.Sections) > 0 || len(partial.UnknownFields) > 0 {
+		return partial
+	}
 
 	return nil
 }
 
-// scrapeProcesses scrapes processes metrics
-func (scr *NginxPlusScraper) scrapeProcesses(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	metrics <- metric.NewMetric("processes_respawned", *status.Processes.Respawned, labels)
+// Families returns the catalog of metrics of nginx plus stats
+func (scr *NginxPlusScraper) Families() []metric.Family {
+	return nginxPlusFamilies
 }
 
-// scrapeConnections scrapes connections metrics
-func (scr *NginxPlusScraper) scrapeConnections(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	metrics <- metric.NewMetric("connections_accepted", status.Connections.Accepted, labels)
-	metrics <- metric.NewMetric("connections_dropped", status.Connections.Dropped, labels)
-	metrics <- metric.NewMetric("connections_active", status.Connections.Active, labels)
-	metrics <- metric.NewMetric("connections_idle", status.Connections.Idle, labels)
+// ResetMetrics returns names of metrics used to detect resets of counters
+func (scr *NginxPlusScraper) ResetMetrics() []string {
+	return []string{"generation", "load_timestamp"}
 }
 
-// scrapeSsl scrapes SSL metrics
-func (scr *NginxPlusScraper) scrapeSsl(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	metrics <- metric.NewMetric("ssl_handshakes", status.Ssl.Handshakes, labels)
-	metrics <- metric.NewMetric("ssl_handshakes_failed", status.Ssl.HandshakesFailed, labels)
-	metrics <- metric.NewMetric("ssl_session_reuses", status.Ssl.SessionReuses, labels)
+// Reset detects the restart of nginx plus by generation and load timestamp of the configuration,
+// the configuration loaded without reload or decreased number of reloads means the restart
+func (scr *NginxPlusScraper) Reset(previous, current []float64) bool {
+	generation, loaded := 0, 1
+
+	if current[generation] < previous[generation] {
+		return true
+	}
+
+	return current[generation] == previous[generation] && current[loaded] != previous[loaded]
 }
 
-func (scr *NginxPlusScraper) scrapeRequest(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	metrics <- metric.NewMetric("requests_total", status.Requests.Total, labels)
-	metrics <- metric.NewMetric("requests_current", status.Requests.Current, labels)
+// stateValue converts state of upstream peer to metric value, states are converted by the table of the scraper
+// when it's set and "up" is 1 and other states are 0 otherwise
+func (scr *NginxPlusScraper) stateValue(state string) float64 {
+	if scr.states == nil {
+		return stateValue(state)
+	}
+
+	return scr.states[strings.ToLower(state)]
 }
 
-// scrapeZone scrapes request metrics
-func (scr *NginxPlusScraper) scrapeZone(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	for zoneName, zone := range status.ServerZones {
-		zoneLabels := make(map[string]string)
-		for k, v := range labels {
-			zoneLabels[k] = v
+// responseCount is the number of responses with status codes of the class
+type responseCount struct {
+	code  string
+	count int64
+}
+
+// responseFamilyNames are names of families suffixed by status code and of the total family by the name of the
+// labelled family of responses, so names aren't concatenated on every scrape
+var responseFamilyNames = newResponseFamilyNames(map[string][]string{
+	"zone_responses":          {"1xx", "2xx", "3xx", "4xx", "5xx"},
+	"upstream_peer_responses": {"1xx", "2xx", "3xx", "4xx", "5xx"},
+	"stream_zone_sessions":    {"2xx", "4xx", "5xx"},
+})
+
+// responseNames are names of families of responses of the labelled family
+type responseNames struct {
+	suffixed map[string]string
+	total    string
+}
+
+// newResponseFamilyNames creates names of families of responses from labelled families and their status codes
+func newResponseFamilyNames(families map[string][]string) map[string]responseNames {
+	result := make(map[string]responseNames, len(families))
+	for name, codes := range families {
+		names := responseNames{suffixed: make(map[string]string, len(codes)), total: name + "_total"}
+		for _, code := range codes {
+			names.suffixed[code] = name + "_" + code
 		}
+		result[name] = names
+	}
 
-		zoneLabels["zone"] = zoneName
-		metrics <- metric.NewMetric("zone_processing", zone.Processing, zoneLabels)
-		metrics <- metric.NewMetric("zone_requests", zone.Requests, zoneLabels)
-		metrics <- metric.NewMetric("zone_received", zone.Received, zoneLabels)
-		metrics <- metric.NewMetric("zone_sent", zone.Sent, zoneLabels)
+	return result
+}
 
-		responseMetric := func(code string, count int64) {
-			codeLabels := make(map[string]string)
-			for k, v := range zoneLabels {
-				codeLabels[k] = v
-			}
-			codeLabels["code"] = code
-			metrics <- metric.NewMetric("zone_responses", count, codeLabels)
-			metrics <- metric.NewMetric("zone_responses_"+code, count, zoneLabels)
+// scrapeResponses scrapes numbers of responses by status code according to the responses mode of the scraper,
+// the total is always exposed as the separate family, so summing up the labelled family doesn't count responses twice.
+// Every status code of the labelled family is the separate series, so it gets its own copy of labels of the entity
+func (scr *NginxPlusScraper) scrapeResponses(name string, total int64, counts []responseCount, metrics chan<- metric.Metric, labels map[string]string) {
+	labelled := scr.responses != SuffixedResponses
+	suffixed := scr.responses != LabelledResponses
+	names := responseFamilyNames[name]
+
+	for _, c := range counts {
+		if labelled {
+			metrics <- metric.NewMetric(name, float64(c.count), withLabel(labels, "code", c.code))
 		}
-		responseMetric("1xx", zone.Responses.Responses1xx)
-		responseMetric("2xx", zone.Responses.Responses2xx)
-		responseMetric("3xx", zone.Responses.Responses3xx)
-		responseMetric("4xx", zone.Responses.Responses4xx)
-		responseMetric("5xx", zone.Responses.Responses5xx)
-		metrics <- metric.NewMetric("zone_responses_total", zone.Responses.Total, zoneLabels)
-
-		if zone.Discarded != nil {
-			metrics <- metric.NewMetric("zone_discarded", *zone.Discarded, zoneLabels)
+		if suffixed {
+			metrics <- metric.NewMetric(names.suffixed[c.code], float64(c.count), labels)
 		}
 	}
+
+	metrics <- metric.NewMetric(names.total, float64(total), labels)
 }
 
-// scrapeUpstream scrapes upstream metrics
-func (scr *NginxPlusScraper) scrapeUpstream(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	for upstreamName, upstream := range status.Upstreams {
-		upstreamLabels := make(map[string]string)
-		for k, v := range labels {
-			upstreamLabels[k] = v
+// scrapeProcesses scrapes processes metrics
+func (scr *NginxPlusScraper) scrapeProcesses(processes *Processes, metrics chan<- metric.Metric, labels map[string]string) {
+	if processes.Respawned != nil {
+		metrics <- metric.NewMetric("processes_respawned", float64(*processes.Respawned), labels)
+	}
+}
+
+// scrapeConnections scrapes connections metrics
+func (scr *NginxPlusScraper) scrapeConnections(connections Connections, metrics chan<- metric.Metric, labels map[string]string) {
+	metrics <- metric.NewMetric("connections_accepted", float64(connections.Accepted), labels)
+	metrics <- metric.NewMetric("connections_dropped", float64(connections.Dropped), labels)
+	metrics <- metric.NewMetric("connections_active", float64(connections.Active), labels)
+	metrics <- metric.NewMetric("connections_idle", float64(connections.Idle), labels)
+}
+
+// scrapeSsl scrapes SSL metrics
+func (scr *NginxPlusScraper) scrapeSsl(ssl *Ssl, metrics chan<- metric.Metric, labels map[string]string) {
+	metrics <- metric.NewMetric("ssl_handshakes", float64(ssl.Handshakes), labels)
+	metrics <- metric.NewMetric("ssl_handshakes_failed", float64(ssl.HandshakesFailed), labels)
+	metrics <- metric.NewMetric("ssl_session_reuses", float64(ssl.SessionReuses), labels)
+}
+
+// scrapeRequest scrapes requests metrics
+func (scr *NginxPlusScraper) scrapeRequest(requests Requests, metrics chan<- metric.Metric, labels map[string]string) {
+	metrics <- metric.NewMetric("requests_total", float64(requests.Total), labels)
+	metrics <- metric.NewMetric("requests_current", float64(requests.Current), labels)
+}
+
+// scrapeZone scrapes metrics of server zone
+func (scr *NginxPlusScraper) scrapeZone(zoneName string, zone ServerZone, metrics chan<- metric.Metric, labels map[string]string) {
+	zoneLabels := withLabel(labels, "zone", zoneName)
+	metrics <- metric.NewMetric("zone_processing", float64(zone.Processing), zoneLabels)
+	metrics <- metric.NewMetric("zone_requests", float64(zone.Requests), zoneLabels)
+	metrics <- metric.NewMetric("zone_received", float64(zone.Received), zoneLabels)
+	metrics <- metric.NewMetric("zone_sent", float64(zone.Sent), zoneLabels)
+
+	scr.scrapeResponses("zone_responses", zone.Responses.Total, []responseCount{
+		{"1xx", zone.Responses.Responses1xx},
+		{"2xx", zone.Responses.Responses2xx},
+		{"3xx", zone.Responses.Responses3xx},
+		{"4xx", zone.Responses.Responses4xx},
+		{"5xx", zone.Responses.Responses5xx},
+	}, metrics, zoneLabels)
+
+	if zone.Discarded != nil {
+		metrics <- metric.NewMetric("zone_discarded", float64(*zone.Discarded), zoneLabels)
+	}
+}
+
+// scrapeUpstream scrapes metrics of upstream and its peers
+func (scr *NginxPlusScraper) scrapeUpstream(upstreamName string, upstream Upstream, metrics chan<- metric.Metric, labels map[string]string) {
+	upstreamLabels := withLabel(labels, "upstream", upstreamName)
+
+	metrics <- metric.NewMetric("upstream_keepalive", float64(upstream.Keepalive), upstreamLabels)
+	if upstream.Zombies != nil {
+		metrics <- metric.NewMetric("upstream_zombies", float64(*upstream.Zombies), upstreamLabels)
+	}
+
+	if upstream.Queue != nil {
+		metrics <- metric.NewMetric("upstream_queue_size", float64(upstream.Queue.Size), upstreamLabels)
+		metrics <- metric.NewMetric("upstream_queue_max_size", float64(upstream.Queue.MaxSize), upstreamLabels)
+		metrics <- metric.NewMetric("upstream_queue_overflows", float64(upstream.Queue.Overflows), upstreamLabels)
+	}
+
+	for _, peer := range upstream.Peers {
+		peerLabels := withLabel(upstreamLabels, "serverAddress", peer.Server)
+
+		metrics <- metric.NewMetric("upstream_peer_backup", boolValue(peer.Backup), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_weight", float64(peer.Weight), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_state", scr.stateValue(peer.State), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_active", float64(peer.Active), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_requests", float64(peer.Requests), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_sent", float64(peer.Sent), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_received", float64(peer.Received), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_fails", float64(peer.Fails), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_unavail", float64(peer.Unavail), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_healthchecks_checks", float64(peer.HealthChecks.Checks), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_healthchecks_fails", float64(peer.HealthChecks.Fails), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_healthchecks_unhealthy", float64(peer.HealthChecks.Unhealthy), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_downtime", float64(peer.Downtime), peerLabels)
+		metrics <- metric.NewMetric("upstream_peer_downstart", float64(peer.Downstart), peerLabels)
+		if peer.Selected != nil {
+			metrics <- metric.NewMetric("upstream_peer_selected", float64(*peer.Selected), peerLabels)
 		}
 
-		upstreamLabels["upstream"] = upstreamName
+		scr.scrapeResponses("upstream_peer_responses", peer.Responses.Total, []responseCount{
+			{"1xx", peer.Responses.Responses1xx},
+			{"2xx", peer.Responses.Responses2xx},
+			{"3xx", peer.Responses.Responses3xx},
+			{"4xx", peer.Responses.Responses4xx},
+			{"5xx", peer.Responses.Responses5xx},
+		}, metrics, peerLabels)
 
-		metrics <- metric.NewMetric("upstream_keepalive", upstream.Keepalive, upstreamLabels)
-		metrics <- metric.NewMetric("upstream_zombies", upstream.Zombies, upstreamLabels)
+		if peer.HealthChecks.LastPassed != nil {
+			metrics <- metric.NewMetric("upstream_peer_healthchecks_last_passed", boolValue(*peer.HealthChecks.LastPassed), peerLabels)
+		}
 
-		if upstream.Queue != nil {
-			metrics <- metric.NewMetric("upstream_queue_size", upstream.Queue.Size, upstreamLabels)
-			metrics <- metric.NewMetric("upstream_queue_max_size", upstream.Queue.MaxSize, upstreamLabels)
-			metrics <- metric.NewMetric("upstream_queue_overflows", upstream.Queue.Overflows, upstreamLabels)
+		if peer.HeaderTime != nil {
+			metrics <- metric.NewMetric("upstream_peer_header_time", float64(*peer.HeaderTime), peerLabels)
 		}
 
-		for _, peer := range upstream.Peers {
-			peerLabels := make(map[string]string)
-			for k, v := range upstreamLabels {
-				peerLabels[k] = v
-			}
-			peerLabels["serverAddress"] = peer.Server
-
-			metrics <- metric.NewMetric("upstream_peer_backup", peer.Backup, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_weight", peer.Weight, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_state", peer.State, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_active", peer.Active, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_requests", peer.Requests, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_sent", peer.Sent, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_received", peer.Received, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_fails", peer.Fails, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_unavail", peer.Unavail, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_healthchecks_checks", peer.HealthChecks.Checks, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_healthchecks_fails", peer.HealthChecks.Fails, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_healthchecks_unhealthy", peer.HealthChecks.Unhealthy, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_downtime", peer.Downtime, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_downstart", peer.Downstart, peerLabels)
-			metrics <- metric.NewMetric("upstream_peer_selected", *peer.Selected, peerLabels)
-
-			responseMetric := func(code string, count int64) {
-				codeLabels := make(map[string]string)
-				for k, v := range peerLabels {
-					codeLabels[k] = v
-				}
-				codeLabels["code"] = code
-				metrics <- metric.NewMetric("upstream_peer_responses", count, codeLabels)
-				metrics <- metric.NewMetric("upstream_peer_responses_"+code, count, peerLabels)
-			}
-			responseMetric("1xx", peer.Responses.Responses1xx)
-			responseMetric("2xx", peer.Responses.Responses2xx)
-			responseMetric("3xx", peer.Responses.Responses3xx)
-			responseMetric("4xx", peer.Responses.Responses4xx)
-			responseMetric("5xx", peer.Responses.Responses5xx)
-			metrics <- metric.NewMetric("upstream_peer_responses_total", peer.Responses.Total, peerLabels)
-
-			if peer.HealthChecks.LastPassed != nil {
-				metrics <- metric.NewMetric("upstream_peer_healthchecks_last_passed", *peer.HealthChecks.LastPassed, peerLabels)
-			}
+		if peer.ResponseTime != nil {
+			metrics <- metric.NewMetric("upstream_peer_response_time", float64(*peer.ResponseTime), peerLabels)
+		}
 
-			if peer.HeaderTime != nil {
-				metrics <- metric.NewMetric("upstream_peer_header_time", *peer.HeaderTime, peerLabels)
-			}
+		if peer.MaxConns != nil {
+			metrics <- metric.NewMetric("upstream_peer_max_conns", float64(*peer.MaxConns), peerLabels)
+		}
+	}
+}
 
-			if peer.ResponseTime != nil {
-				metrics <- metric.NewMetric("upstream_peer_response_time", *peer.ResponseTime, peerLabels)
-			}
+// scrapeCache scrapes metrics of cache
+func (scr *NginxPlusScraper) scrapeCache(cacheName string, cache Cache, metrics chan<- metric.Metric, labels map[string]string) {
+	cacheLabels := withLabel(labels, "cache", cacheName)
+
+	metrics <- metric.NewMetric("cache_size", float64(cache.Size), cacheLabels)
+	metrics <- metric.NewMetric("cache_max_size", float64(cache.Size), cacheLabels)
+	metrics <- metric.NewMetric("cache_cold", boolValue(cache.Cold), cacheLabels)
+	metrics <- metric.NewMetric("cache_hit_responses", float64(cache.Hit.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_hit_bytes", float64(cache.Hit.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_stale_responses", float64(cache.Stale.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_stale_bytes", float64(cache.Stale.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_updating_responses", float64(cache.Updating.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_updating_bytes", float64(cache.Updating.Bytes), cacheLabels)
+	if cache.Revalidated != nil {
+		metrics <- metric.NewMetric("cache_revalidated_responses", float64(cache.Revalidated.Responses), cacheLabels)
+		metrics <- metric.NewMetric("cache_revalidated_bytes", float64(cache.Revalidated.Bytes), cacheLabels)
+	}
+	metrics <- metric.NewMetric("cache_miss_responses", float64(cache.Miss.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_miss_bytes", float64(cache.Miss.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_miss_responses_written", float64(cache.Miss.ResponsesWritten), cacheLabels)
+	metrics <- metric.NewMetric("cache_miss_bytes_written", float64(cache.Miss.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_expired_responses", float64(cache.Expired.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_expired_bytes", float64(cache.Expired.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_expired_responses_written", float64(cache.Expired.ResponsesWritten), cacheLabels)
+	metrics <- metric.NewMetric("cache_expired_bytes_written", float64(cache.Expired.BytesWritten), cacheLabels)
+	metrics <- metric.NewMetric("cache_responses", float64(cache.Bypass.Responses), cacheLabels)
+	metrics <- metric.NewMetric("cache_bytes", float64(cache.Bypass.Bytes), cacheLabels)
+	metrics <- metric.NewMetric("cache_responses_written", float64(cache.Bypass.ResponsesWritten), cacheLabels)
+	metrics <- metric.NewMetric("cache_bytes_written", float64(cache.Bypass.BytesWritten), cacheLabels)
+}
 
-			if peer.MaxConns != nil {
-				metrics <- metric.NewMetric("upstream_peer_max_conns", *peer.MaxConns, peerLabels)
-			}
+// scrapeStream decodes stream stats and scrapes metrics of every stream server zone and upstream,
+// zones and upstreams of unexpected types are skipped and reported as the partial error
+func (scr *NginxPlusScraper) scrapeStream(dec *json.Decoder, version int, partial *PartialError, metrics chan<- metric.Metric, labels map[string]string) error {
+	return decodeObject(dec, func(key string) error {
+		scr.checkField("stream", key, streamType, version, partial)
+
+		switch key {
+		case "server_zones":
+			return partial.skip("stream", decodeObject(dec, func(zoneName string) error {
+				zone := StreamServerZone{}
+				if err := scr.decode(dec, "stream.server_zones.*", &zone, version, partial); err != nil {
+					return partial.skip("stream", err)
+				}
+				scr.scrapeStreamZone(zoneName, zone, metrics, labels)
+				return nil
+			}))
+		case "upstreams":
+			return partial.skip("stream", decodeObject(dec, func(upstreamName string) error {
+				upstream := StreamUpstream{}
+				if err := scr.decode(dec, "stream.upstreams.*", &upstream, version, partial); err != nil {
+					return partial.skip("stream", err)
+				}
+				scr.scrapeStreamUpstream(upstreamName, upstream, metrics, labels)
+				return nil
+			}))
+		default:
+			return skipValue(dec)
 		}
+	})
+}
+
+// scrapeStreamZone scrapes metrics of stream server zone
+func (scr *NginxPlusScraper) scrapeStreamZone(zoneName string, zone StreamServerZone, metrics chan<- metric.Metric, labels map[string]string) {
+	zoneLabels := withLabel(labels, "zone", zoneName)
+
+	metrics <- metric.NewMetric("stream_zone_processing", float64(zone.Processing), zoneLabels)
+	metrics <- metric.NewMetric("stream_zone_connections", float64(zone.Connections), zoneLabels)
+	metrics <- metric.NewMetric("stream_zone_received", float64(zone.Received), zoneLabels)
+	metrics <- metric.NewMetric("stream_zone_sent", float64(zone.Sent), zoneLabels)
+
+	if zone.Sessions != nil {
+		scr.scrapeResponses("stream_zone_sessions", zone.Sessions.Total, []responseCount{
+			{"2xx", zone.Sessions.Sessions2xx},
+			{"4xx", zone.Sessions.Sessions4xx},
+			{"5xx", zone.Sessions.Sessions5xx},
+		}, metrics, zoneLabels)
 	}
 }
 
-// scrapeCache scrapes cache metrics
-func (scr *NginxPlusScraper) scrapeCache(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	for cacheName, cache := range status.Caches {
-		cacheLabels := make(map[string]string)
-		for k, v := range labels {
-			cacheLabels[k] = v
+// scrapeStreamUpstream scrapes metrics of stream upstream and its peers
+func (scr *NginxPlusScraper) scrapeStreamUpstream(upstreamName string, upstream StreamUpstream, metrics chan<- metric.Metric, labels map[string]string) {
+	upstreamLabels := withLabel(labels, "upstream", upstreamName)
+
+	metrics <- metric.NewMetric("stream_upstream_zombies", float64(upstream.Zombies), upstreamLabels)
+
+	for _, peer := range upstream.Peers {
+		peerLables := withLabel(upstreamLabels, "serverAddress", peer.Server)
+
+		metrics <- metric.NewMetric("stream_upstream_peer_backup", boolValue(peer.Backup), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_weight", float64(peer.Weight), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_state", scr.stateValue(peer.State), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_active", float64(peer.Active), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_connections", float64(peer.Connections), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_sent", float64(peer.Sent), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_received", float64(peer.Received), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_fails", float64(peer.Fails), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_unavail", float64(peer.Unavail), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_checks", float64(peer.HealthChecks.Checks), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_fails", float64(peer.HealthChecks.Fails), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_unhealthy", float64(peer.HealthChecks.Unhealthy), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_downtime", float64(peer.Downtime), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_downstart", float64(peer.Downstart), peerLables)
+		metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_selected", float64(peer.Selected), peerLables)
+
+		if peer.HealthChecks.LastPassed != nil {
+			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_last_passed", boolValue(*peer.HealthChecks.LastPassed), peerLables)
+		}
+		if peer.ConnectTime != nil {
+			metrics <- metric.NewMetric("stream_upstream_peer_connect_time", float64(*peer.ConnectTime), peerLables)
+		}
+		if peer.FirstByteTime != nil {
+			metrics <- metric.NewMetric("stream_upstream_peer_first_byte_time", float64(*peer.FirstByteTime), peerLables)
+		}
+		if peer.ResponseTime != nil {
+			metrics <- metric.NewMetric("stream_upstream_peer_response_time", float64(*peer.ResponseTime), peerLables)
 		}
-		cacheLabels["cache"] = cacheName
-
-		metrics <- metric.NewMetric("cache_size", cache.Size, cacheLabels)
-		metrics <- metric.NewMetric("cache_max_size", cache.Size, cacheLabels)
-		metrics <- metric.NewMetric("cache_cold", cache.Cold, cacheLabels)
-		metrics <- metric.NewMetric("cache_hit_responses", cache.Hit.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_hit_bytes", cache.Hit.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_stale_responses", cache.Stale.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_stale_bytes", cache.Stale.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_updating_responses", cache.Updating.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_updating_bytes", cache.Updating.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_revalidated_responses", cache.Revalidated.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_revalidated_bytes", cache.Revalidated.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_miss_responses", cache.Miss.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_miss_bytes", cache.Miss.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_miss_responses_written", cache.Miss.ResponsesWritten, cacheLabels)
-		metrics <- metric.NewMetric("cache_miss_bytes_written", cache.Miss.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_expired_responses", cache.Expired.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_expired_bytes", cache.Expired.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_expired_responses_written", cache.Expired.ResponsesWritten, cacheLabels)
-		metrics <- metric.NewMetric("cache_expired_bytes_written", cache.Expired.BytesWritten, cacheLabels)
-		metrics <- metric.NewMetric("cache_responses", cache.Bypass.Responses, cacheLabels)
-		metrics <- metric.NewMetric("cache_bytes", cache.Bypass.Bytes, cacheLabels)
-		metrics <- metric.NewMetric("cache_responses_written", cache.Bypass.ResponsesWritten, cacheLabels)
-		metrics <- metric.NewMetric("cache_bytes_written", cache.Bypass.BytesWritten, cacheLabels)
 	}
 }
 
-// scrapeStream scrapes stream metrics
-func (scr *NginxPlusScraper) scrapeStream(status *Status, metrics chan<- metric.Metric, labels map[string]string) {
-	for zoneName, zone := range status.Stream.ServerZones {
-		zoneLabels := map[string]string{}
-		for k, v := range labels {
-			zoneLabels[k] = v
+// decodeObject decodes JSON object from the stream calling decodeValue for every key,
+// decodeValue has to decode the value of the key, null is decoded as empty object and
+// values of other types are skipped with errUnexpectedValue
+func decodeObject(dec *json.Decoder, decodeValue func(key string) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+	case nil:
+		return nil
+	case json.Delim('['):
+		if err := skipNested(dec, 1); err != nil {
+			return err
+		}
+		return errUnexpectedValue
+	default:
+		return errUnexpectedValue
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
 		}
-		zoneLabels["zone"] = zoneName
 
-		metrics <- metric.NewMetric("stream_zone_processing", zone.Processing, zoneLabels)
-		metrics <- metric.NewMetric("stream_zone_connections", zone.Connections, zoneLabels)
-		metrics <- metric.NewMetric("stream_zone_received", zone.Received, zoneLabels)
-		metrics <- metric.NewMetric("stream_zone_sent", zone.Sent, zoneLabels)
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v instead of object key", token)
+		}
+
+		if err := decodeValue(key); err != nil {
+			return err
+		}
 	}
 
-	for upstreamName, upstream := range status.Stream.Upstreams {
-		upstreamLabels := map[string]string{}
-		for k, v := range labels {
-			upstreamLabels[k] = v
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token which has to be the delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("unexpected token %v instead of '%s'", token, delim)
+	}
+
+	return nil
+}
+
+// skipValue reads and drops the next value of the stream token by token without keeping it in memory
+func skipValue(dec *json.Decoder) error {
+	return skipNested(dec, 0)
+}
+
+// skipNested reads and drops tokens of the stream until the depth of nested objects and arrays gets zero
+func skipNested(dec *json.Decoder, depth int) error {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
 		}
-		upstreamLabels["upstream"] = upstreamName
 
-		metrics <- metric.NewMetric("stream_upstream_zombies", upstream.Zombies, upstreamLabels)
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
 
-		for _, peer := range upstream.Peers {
-			peerLables := map[string]string{}
-			for k, v := range upstreamLabels {
-				peerLables[k] = v
-			}
-			peerLables["serverAddress"] = peer.Server
-
-			metrics <- metric.NewMetric("stream_upstream_peer_backup", peer.Backup, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_weight", peer.Weight, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_state", peer.State, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_active", peer.Active, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_connections", peer.Connections, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_sent", peer.Sent, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_received", peer.Received, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_fails", peer.Fails, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_unavail", peer.Unavail, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_checks", peer.HealthChecks.Checks, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_fails", peer.HealthChecks.Fails, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_unhealthy", peer.HealthChecks.Unhealthy, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_downtime", peer.Downtime, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_downstart", peer.Downstart, peerLables)
-			metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_selected", peer.Selected, peerLables)
-
-			if peer.HealthChecks.LastPassed != nil {
-				metrics <- metric.NewMetric("stream_upstream_peer_healthchecks_last_passed", *peer.HealthChecks.LastPassed, peerLables)
-			}
-			if peer.ConnectTime != nil {
-				metrics <- metric.NewMetric("stream_upstream_peer_connect_time", *peer.ConnectTime, peerLables)
-			}
-			if peer.FirstByteTime != nil {
-				metrics <- metric.NewMetric("stream_upstream_peer_first_byte_time", *peer.FirstByteTime, peerLables)
-			}
-			if peer.ResponseTime != nil {
-				metrics <- metric.NewMetric("stream_upstream_peer_response_time", *peer.ResponseTime, peerLables)
-			}
+		if depth == 0 {
+			return nil
 		}
 	}
 }
@@ -292,25 +599,25 @@ type Status struct {
 	Version       int    `json:"version"`
 	NginxVersion  string `json:"nginx_version"`
 	Address       string `json:"address"`
-	Generation    *int   `json:"generation"`     // added in version 5
-	LoadTimestamp *int64 `json:"load_timestamp"` // added in version 2
+	Generation    *int   `json:"generation" since:"5"`     // added in version 5
+	LoadTimestamp *int64 `json:"load_timestamp" since:"2"` // added in version 2
 	Timestamp     int64  `json:"timestamp"`
-	Pid           *int   `json:"pid"` // added in version 6
+	Pid           *int   `json:"pid" since:"6"` // added in version 6
 
 	Processes   *Processes  `json:"processes"`
 	Connections Connections `json:"connections"`
-	Ssl         *Ssl        `json:"ssl"`
+	Ssl         *Ssl        `json:"ssl" since:"6"`
 	Requests    Requests    `json:"requests"`
-	ServerZones ServerZones `json:"server_zones"`
+	ServerZones ServerZones `json:"server_zones" since:"2"`
 	Upstreams   Upstreams   `json:"upstreams"`
-	Caches      Caches      `json:"caches"`
+	Caches      Caches      `json:"caches" since:"2"`
 	Stream      Stream      `json:"stream"`
 }
 
 // Processes contains the total number of respawned child processes.
 type Processes struct {
 	// added in version 5
-	Respawned *int `json:"respawned"`
+	Respawned *int `json:"respawned" since:"5"`
 }
 
 // Connections contains the total number of accepted, dropped, active and idle client connections.
@@ -337,7 +644,10 @@ type Requests struct {
 
 // ServerZones contains info about processed requests, requests received from clients, number of responses from clients
 // with http statuses, total number of requests completed without sending a response, number of bytes received and sent.
-type ServerZones map[string]struct {
+type ServerZones map[string]ServerZone
+
+// ServerZone contains stats of the single server zone.
+type ServerZone struct {
 	// added in version 2
 	Processing int   `json:"processing"`
 	Requests   int64 `json:"requests"`
@@ -349,23 +659,26 @@ type ServerZones map[string]struct {
 		Responses5xx int64 `json:"5xx"`
 		Total        int64 `json:"total"`
 	} `json:"responses"`
-	Discarded *int64 `json:"discarded"` // added in version 6
+	Discarded *int64 `json:"discarded" since:"6"` // added in version 6
 	Received  int64  `json:"received"`
 	Sent      int64  `json:"sent"`
 }
 
 // Upstreams contains a lot of information about upstreams, like: peers info, current number of idle keepalive
 // connections, total number of zombies, the size of requests queue.
-type Upstreams map[string]struct {
+type Upstreams map[string]Upstream
+
+// Upstream contains stats of the single upstream and its peers.
+type Upstream struct {
 	Peers []struct {
-		ID        *int   `json:"id"` // added in version 3
+		ID        *int   `json:"id" since:"3"` // added in version 3
 		Server    string `json:"server"`
 		Backup    bool   `json:"backup"`
 		Weight    int    `json:"weight"`
 		State     string `json:"state"`
 		Active    int    `json:"active"`
-		Keepalive *int   `json:"keepalive"` // removed in version 5
-		MaxConns  *int   `json:"max_conns"` // added in version 3
+		Keepalive *int   `json:"keepalive" until:"4"` // removed in version 5
+		MaxConns  *int   `json:"max_conns" since:"3"` // added in version 3
 		Requests  int64  `json:"requests"`
 		Responses struct {
 			Responses1xx int64 `json:"1xx"`
@@ -387,24 +700,27 @@ type Upstreams map[string]struct {
 		} `json:"health_checks"`
 		Downtime     int64  `json:"downtime"`
 		Downstart    int64  `json:"downstart"`
-		Selected     *int64 `json:"selected"`      // added in version 4
-		HeaderTime   *int64 `json:"header_time"`   // added in version 5
-		ResponseTime *int64 `json:"response_time"` // added in version 5
+		Selected     *int64 `json:"selected" since:"4"`      // added in version 4
+		HeaderTime   *int64 `json:"header_time" since:"5"`   // added in version 5
+		ResponseTime *int64 `json:"response_time" since:"5"` // added in version 5
 	} `json:"peers"`
-	Keepalive int `json:"keepalive"`
-	Zombies   int `json:"zombies"` // added in version 6
+	Keepalive int  `json:"keepalive"`
+	Zombies   *int `json:"zombies" since:"6"` // added in version 6
 	Queue     *struct {
 		// added in version 6
 		Size      int   `json:"size"`
 		MaxSize   int   `json:"max_size"`
 		Overflows int64 `json:"overflows"`
-	} `json:"queue"`
+	} `json:"queue" since:"6"`
 }
 
 // Caches contains a lot of information of cache, like: current size of cache, the limit on the maximum size of the
 // cache, total number of responses and total number of bytes read from the cache, total number of requests not read
 // from the cache and number of bytes read from proxied server, number of responses and bytes written to the cache.
-type Caches map[string]struct {
+type Caches map[string]Cache
+
+// Cache contains stats of the single cache.
+type Cache struct {
 	// added in version 2
 	Size    int64 `json:"size"`
 	MaxSize int64 `json:"max_size"`
@@ -425,7 +741,7 @@ type Caches map[string]struct {
 		// added in version 3
 		Responses int64 `json:"responses"`
 		Bytes     int64 `json:"bytes"`
-	} `json:"revalidated"`
+	} `json:"revalidated" since:"3"`
 	Miss struct {
 		Responses        int64 `json:"responses"`
 		Bytes            int64 `json:"bytes"`
@@ -451,47 +767,53 @@ type Caches map[string]struct {
 // a session, number of bytes received from clients, total number of bytes sent to clients and more information about
 // upstreams.
 type Stream struct {
-	ServerZones map[string]struct {
-		Processing  int `json:"processing"`
-		Connections int `json:"connections"`
-		Sessions    *struct {
-			Total       int64 `json:"total"`
-			Sessions1xx int64 `json:"1xx"`
-			Sessions2xx int64 `json:"2xx"`
-			Sessions3xx int64 `json:"3xx"`
-			Sessions4xx int64 `json:"4xx"`
-			Sessions5xx int64 `json:"5xx"`
-		} `json:"sessions"`
-		Discarded *int64 `json:"discarded"` // added in version 7
-		Received  int64  `json:"received"`
-		Sent      int64  `json:"sent"`
-	} `json:"server_zones"`
-	Upstreams map[string]struct {
-		Peers []struct {
-			ID            int    `json:"id"`
-			Server        string `json:"server"`
-			Backup        bool   `json:"backup"`
-			Weight        int    `json:"weight"`
-			State         string `json:"state"`
-			Active        int    `json:"active"`
-			Connections   int64  `json:"connections"`
-			ConnectTime   *int   `json:"connect_time"`
-			FirstByteTime *int   `json:"first_byte_time"`
-			ResponseTime  *int   `json:"response_time"`
-			Sent          int64  `json:"sent"`
-			Received      int64  `json:"received"`
-			Fails         int64  `json:"fails"`
-			Unavail       int64  `json:"unavail"`
-			HealthChecks  struct {
-				Checks     int64 `json:"checks"`
-				Fails      int64 `json:"fails"`
-				Unhealthy  int64 `json:"unhealthy"`
-				LastPassed *bool `json:"last_passed"`
-			} `json:"health_checks"`
-			Downtime  int64 `json:"downtime"`
-			Downstart int64 `json:"downstart"`
-			Selected  int64 `json:"selected"`
-		} `json:"peers"`
-		Zombies int `json:"zombies"`
-	} `json:"upstreams"`
+	ServerZones map[string]StreamServerZone `json:"server_zones"`
+	Upstreams   map[string]StreamUpstream   `json:"upstreams"`
+}
+
+// StreamServerZone contains stats of the single stream server zone.
+type StreamServerZone struct {
+	Processing  int `json:"processing"`
+	Connections int `json:"connections"`
+	Sessions    *struct {
+		Total       int64 `json:"total"`
+		Sessions1xx int64 `json:"1xx"`
+		Sessions2xx int64 `json:"2xx"`
+		Sessions3xx int64 `json:"3xx"`
+		Sessions4xx int64 `json:"4xx"`
+		Sessions5xx int64 `json:"5xx"`
+	} `json:"sessions"`
+	Discarded *int64 `json:"discarded" since:"7"` // added in version 7
+	Received  int64  `json:"received"`
+	Sent      int64  `json:"sent"`
+}
+
+// StreamUpstream contains stats of the single stream upstream and its peers.
+type StreamUpstream struct {
+	Peers []struct {
+		ID            int    `json:"id"`
+		Server        string `json:"server"`
+		Backup        bool   `json:"backup"`
+		Weight        int    `json:"weight"`
+		State         string `json:"state"`
+		Active        int    `json:"active"`
+		Connections   int64  `json:"connections"`
+		ConnectTime   *int   `json:"connect_time"`
+		FirstByteTime *int   `json:"first_byte_time"`
+		ResponseTime  *int   `json:"response_time"`
+		Sent          int64  `json:"sent"`
+		Received      int64  `json:"received"`
+		Fails         int64  `json:"fails"`
+		Unavail       int64  `json:"unavail"`
+		HealthChecks  struct {
+			Checks     int64 `json:"checks"`
+			Fails      int64 `json:"fails"`
+			Unhealthy  int64 `json:"unhealthy"`
+			LastPassed *bool `json:"last_passed"`
+		} `json:"health_checks"`
+		Downtime  int64 `json:"downtime"`
+		Downstart int64 `json:"downstart"`
+		Selected  int64 `json:"selected"`
+	} `json:"peers"`
+	Zombies int `json:"zombies"`
 }
@@ -1,6 +1,8 @@
 package scraper_test
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -205,7 +207,7 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 	nginxPlusScraper := scraper.NewNginxPlusScraper()
 	reader := strings.NewReader(validNginxPlusStats)
 
-	metrics := make(chan metric.Metric, 108)
+	metrics := make(chan metric.Metric, 110)
 	labels := map[string]string{
 		"host": "zone.a_80",
 		"port": "8080",
@@ -215,53 +217,63 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 	c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
 
 	m := <-metrics
+	c.Assert(m.Name, Equals, "generation", Commentf("incorrect metrics name of 'generation' field"))
+	c.Assert(m.Value, Equals, float64(88), Commentf("incorrect value of metric 'generation'"))
+	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "load_timestamp", Commentf("incorrect metrics name of 'load_timestamp' field"))
+	c.Assert(m.Value, Equals, float64(1451606400000), Commentf("incorrect value of metric 'load_timestamp'"))
+	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
+
+	m = <-metrics
 	c.Assert(m.Name, Equals, "processes_respawned", Commentf("incorrect metrics name of 'processes_respawned' field"))
-	c.Assert(m.Value, Equals, 9999, Commentf("incorrect value of metric 'processes_respawned'"))
+	c.Assert(m.Value, Equals, float64(9999), Commentf("incorrect value of metric 'processes_respawned'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "connections_accepted", Commentf("incorrect metrics name of 'connections_accepted' field"))
-	c.Assert(m.Value, Equals, 1234567890000, Commentf("incorrect value of metric 'connections_accepted'"))
+	c.Assert(m.Value, Equals, float64(1234567890000), Commentf("incorrect value of metric 'connections_accepted'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "connections_dropped", Commentf("incorrect metrics name of 'connections_dropped' field"))
-	c.Assert(m.Value, Equals, 2345678900000, Commentf("incorrect value of metric 'connections_dropped'"))
+	c.Assert(m.Value, Equals, float64(2345678900000), Commentf("incorrect value of metric 'connections_dropped'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "connections_active", Commentf("incorrect metrics name of 'connections_active' field"))
-	c.Assert(m.Value, Equals, 345, Commentf("incorrect value of metric 'connections_active'"))
+	c.Assert(m.Value, Equals, float64(345), Commentf("incorrect value of metric 'connections_active'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "connections_idle", Commentf("incorrect metrics name of 'connections_idle' field"))
-	c.Assert(m.Value, Equals, 567, Commentf("incorrect value of metric 'connections_idle'"))
+	c.Assert(m.Value, Equals, float64(567), Commentf("incorrect value of metric 'connections_idle'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "ssl_handshakes", Commentf("incorrect metrics name of 'ssl_handshakes' field"))
-	c.Assert(m.Value, Equals, int64(1234567800000), Commentf("incorrect value of metric 'ssl_handshakes'"))
+	c.Assert(m.Value, Equals, float64(1234567800000), Commentf("incorrect value of metric 'ssl_handshakes'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "ssl_handshakes_failed", Commentf("incorrect metrics name of 'ssl_handshakes_failed' field"))
-	c.Assert(m.Value, Equals, int64(5432100000000), Commentf("incorrect value of metric 'ssl_handshakes_failed'"))
+	c.Assert(m.Value, Equals, float64(5432100000000), Commentf("incorrect value of metric 'ssl_handshakes_failed'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "ssl_session_reuses", Commentf("incorrect metrics name of 'ssl_session_reuses' field"))
-	c.Assert(m.Value, Equals, int64(6543210000000), Commentf("incorrect value of metric 'ssl_session_reuses'"))
+	c.Assert(m.Value, Equals, float64(6543210000000), Commentf("incorrect value of metric 'ssl_session_reuses'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "requests_total", Commentf("incorrect metrics name of 'requests_total' field"))
-	c.Assert(m.Value, Equals, int64(9876543210000), Commentf("incorrect value of metric 'requests_total'"))
+	c.Assert(m.Value, Equals, float64(9876543210000), Commentf("incorrect value of metric 'requests_total'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "requests_current", Commentf("incorrect metrics name of 'requests_current' field"))
-	c.Assert(m.Value, Equals, int(98), Commentf("incorrect value of metric 'requests_current'"))
+	c.Assert(m.Value, Equals, float64(98), Commentf("incorrect value of metric 'requests_current'"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	zoneLabels := make(map[string]string)
@@ -272,82 +284,82 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_processing", Commentf("incorrect metrics name of 'zone_processing' field"))
-	c.Assert(m.Value, Equals, 12, Commentf("incorrect value of metric 'zone_processing'"))
+	c.Assert(m.Value, Equals, float64(12), Commentf("incorrect value of metric 'zone_processing'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_requests", Commentf("incorrect metrics name of 'zone_requests' field"))
-	c.Assert(m.Value, Equals, int64(34), Commentf("incorrect value of metric 'zone_requests'"))
+	c.Assert(m.Value, Equals, float64(34), Commentf("incorrect value of metric 'zone_requests'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_received", Commentf("incorrect metrics name of 'zone_received' field"))
-	c.Assert(m.Value, Equals, int64(22), Commentf("incorrect value of metric 'zone_received'"))
+	c.Assert(m.Value, Equals, float64(22), Commentf("incorrect value of metric 'zone_received'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_sent", Commentf("incorrect metrics name of 'zone_sent' field"))
-	c.Assert(m.Value, Equals, int64(33), Commentf("incorrect value of metric 'zone_sent'"))
+	c.Assert(m.Value, Equals, float64(33), Commentf("incorrect value of metric 'zone_sent'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses", Commentf("incorrect metrics name of 'zone_responses' field"))
-	c.Assert(m.Value, Equals, int64(111), Commentf("incorrect value of metric 'zone_responses'"))
+	c.Assert(m.Value, Equals, float64(111), Commentf("incorrect value of metric 'zone_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(zoneLabels, "1xx"), Commentf("incorrect set of labels")) // 1xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_1xx", Commentf("incorrect metrics name of 'zone_responses_1xx' field"))
-	c.Assert(m.Value, Equals, int64(111), Commentf("incorrect value of metric 'zone_responses_1xx'"))
+	c.Assert(m.Value, Equals, float64(111), Commentf("incorrect value of metric 'zone_responses_1xx'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses", Commentf("incorrect metrics name of 'zone_responses' field"))
-	c.Assert(m.Value, Equals, int64(222), Commentf("incorrect value of metric 'zone_responses'"))
+	c.Assert(m.Value, Equals, float64(222), Commentf("incorrect value of metric 'zone_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(zoneLabels, "2xx"), Commentf("incorrect set of labels")) // 2xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_2xx", Commentf("incorrect metrics name of 'zone_responses_2xx' field"))
-	c.Assert(m.Value, Equals, int64(222), Commentf("incorrect value of metric 'zone_responses_2xx'"))
+	c.Assert(m.Value, Equals, float64(222), Commentf("incorrect value of metric 'zone_responses_2xx'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses", Commentf("incorrect metrics name of 'zone_responses' field"))
-	c.Assert(m.Value, Equals, int64(333), Commentf("incorrect value of metric 'zone_responses'"))
+	c.Assert(m.Value, Equals, float64(333), Commentf("incorrect value of metric 'zone_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(zoneLabels, "3xx"), Commentf("incorrect set of labels")) // 3xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_3xx", Commentf("incorrect metrics name of 'zone_responses_3xx' field"))
-	c.Assert(m.Value, Equals, int64(333), Commentf("incorrect value of metric 'zone_responses_3xx'"))
+	c.Assert(m.Value, Equals, float64(333), Commentf("incorrect value of metric 'zone_responses_3xx'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses", Commentf("incorrect metrics name of 'zone_responses' field"))
-	c.Assert(m.Value, Equals, int64(444), Commentf("incorrect value of metric 'zone_responses'"))
+	c.Assert(m.Value, Equals, float64(444), Commentf("incorrect value of metric 'zone_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(zoneLabels, "4xx"), Commentf("incorrect set of labels")) // 4xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_4xx", Commentf("incorrect metrics name of 'zone_responses_4xx' field"))
-	c.Assert(m.Value, Equals, int64(444), Commentf("incorrect value of metric 'zone_responses_4xx'"))
+	c.Assert(m.Value, Equals, float64(444), Commentf("incorrect value of metric 'zone_responses_4xx'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses", Commentf("incorrect metrics name of 'zone_responses' field"))
-	c.Assert(m.Value, Equals, int64(555), Commentf("incorrect value of metric 'zone_responses'"))
+	c.Assert(m.Value, Equals, float64(555), Commentf("incorrect value of metric 'zone_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(zoneLabels, "5xx"), Commentf("incorrect set of labels")) // 5xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_5xx", Commentf("incorrect metrics name of 'zone_responses_5xx' field"))
-	c.Assert(m.Value, Equals, int64(555), Commentf("incorrect value of metric 'zone_responses_5xx'"))
+	c.Assert(m.Value, Equals, float64(555), Commentf("incorrect value of metric 'zone_responses_5xx'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_responses_total", Commentf("incorrect metrics name of 'zone_responses_total' field"))
-	c.Assert(m.Value, Equals, int64(999), Commentf("incorrect value of metric 'zone_responses_total'"))
+	c.Assert(m.Value, Equals, float64(999), Commentf("incorrect value of metric 'zone_responses_total'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "zone_discarded", Commentf("incorrect metrics name of 'zone_discarded' field"))
-	c.Assert(m.Value, Equals, int64(11), Commentf("incorrect value of metric 'zone_discarded'"))
+	c.Assert(m.Value, Equals, float64(11), Commentf("incorrect value of metric 'zone_discarded'"))
 	c.Assert(m.Labels, DeepEquals, zoneLabels, Commentf("incorrect set of labels"))
 
 	upstreamLabels := make(map[string]string)
@@ -358,27 +370,27 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_keepalive", Commentf("incorrect metrics name of 'upstream_keepalive' field"))
-	c.Assert(m.Value, Equals, 1, Commentf("incorrect value of metric 'upstream_keepalive'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'upstream_keepalive'"))
 	c.Assert(m.Labels, DeepEquals, upstreamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_zombies", Commentf("incorrect metrics name of 'upstream_zombies' field"))
-	c.Assert(m.Value, Equals, 2, Commentf("incorrect value of metric 'upstream_zombies'"))
+	c.Assert(m.Value, Equals, float64(2), Commentf("incorrect value of metric 'upstream_zombies'"))
 	c.Assert(m.Labels, DeepEquals, upstreamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_queue_size", Commentf("incorrect metrics name of 'upstream_queue_size' field"))
-	c.Assert(m.Value, Equals, 100, Commentf("incorrect value of metric 'upstream_queue_size'"))
+	c.Assert(m.Value, Equals, float64(100), Commentf("incorrect value of metric 'upstream_queue_size'"))
 	c.Assert(m.Labels, DeepEquals, upstreamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_queue_max_size", Commentf("incorrect metrics name of 'upstream_queue_max_size' field"))
-	c.Assert(m.Value, Equals, 1000, Commentf("incorrect value of metric 'upstream_queue_max_size'"))
+	c.Assert(m.Value, Equals, float64(1000), Commentf("incorrect value of metric 'upstream_queue_max_size'"))
 	c.Assert(m.Labels, DeepEquals, upstreamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_queue_overflows", Commentf("incorrect metrics name of 'upstream_queue_overflows' field"))
-	c.Assert(m.Value, Equals, int64(12), Commentf("incorrect value of metric 'upstream_queue_overflows'"))
+	c.Assert(m.Value, Equals, float64(12), Commentf("incorrect value of metric 'upstream_queue_overflows'"))
 	c.Assert(m.Labels, DeepEquals, upstreamLabels, Commentf("incorrect set of labels"))
 
 	peerLabels := make(map[string]string)
@@ -389,152 +401,152 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_backup", Commentf("incorrect metrics name of 'upstream_peer_backup' field"))
-	c.Assert(m.Value, Equals, false, Commentf("incorrect value of metric 'upstream_peer_backup'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'upstream_peer_backup'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_weight", Commentf("incorrect metrics name of 'upstream_peer_weight' field"))
-	c.Assert(m.Value, Equals, 1, Commentf("incorrect value of metric 'upstream_peer_weight'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'upstream_peer_weight'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_state", Commentf("incorrect metrics name of 'upstream_peer_state' field"))
-	c.Assert(m.Value, Equals, "up", Commentf("incorrect value of metric 'upstream_peer_state'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'upstream_peer_state'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_active", Commentf("incorrect metrics name of 'upstream_peer_active' field"))
-	c.Assert(m.Value, Equals, 0, Commentf("incorrect value of metric 'upstream_peer_active'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'upstream_peer_active'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_requests", Commentf("incorrect metrics name of 'upstream_peer_requests' field"))
-	c.Assert(m.Value, Equals, int64(9876), Commentf("incorrect value of metric 'upstream_peer_requests'"))
+	c.Assert(m.Value, Equals, float64(9876), Commentf("incorrect value of metric 'upstream_peer_requests'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_sent", Commentf("incorrect metrics name of 'upstream_peer_sent' field"))
-	c.Assert(m.Value, Equals, int64(987654321), Commentf("incorrect value of metric 'upstream_peer_sent'"))
+	c.Assert(m.Value, Equals, float64(987654321), Commentf("incorrect value of metric 'upstream_peer_sent'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_received", Commentf("incorrect metrics name of 'upstream_peer_received' field"))
-	c.Assert(m.Value, Equals, int64(87654321), Commentf("incorrect value of metric 'upstream_peer_received'"))
+	c.Assert(m.Value, Equals, float64(87654321), Commentf("incorrect value of metric 'upstream_peer_received'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_fails", Commentf("incorrect metrics name of 'upstream_peer_fails' field"))
-	c.Assert(m.Value, Equals, int64(98), Commentf("incorrect value of metric 'upstream_peer_fails'"))
+	c.Assert(m.Value, Equals, float64(98), Commentf("incorrect value of metric 'upstream_peer_fails'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_unavail", Commentf("incorrect metrics name of 'upstream_peer_unavail' field"))
-	c.Assert(m.Value, Equals, int64(65), Commentf("incorrect value of metric 'upstream_peer_unavail'"))
+	c.Assert(m.Value, Equals, float64(65), Commentf("incorrect value of metric 'upstream_peer_unavail'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_healthchecks_checks", Commentf("incorrect metrics name of 'upstream_peer_healthchecks_checks' field"))
-	c.Assert(m.Value, Equals, int64(54), Commentf("incorrect value of metric 'upstream_peer_healthchecks_checks'"))
+	c.Assert(m.Value, Equals, float64(54), Commentf("incorrect value of metric 'upstream_peer_healthchecks_checks'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_healthchecks_fails", Commentf("incorrect metrics name of 'upstream_peer_healthchecks_fails' field"))
-	c.Assert(m.Value, Equals, int64(32), Commentf("incorrect value of metric 'upstream_peer_healthchecks_fails'"))
+	c.Assert(m.Value, Equals, float64(32), Commentf("incorrect value of metric 'upstream_peer_healthchecks_fails'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_healthchecks_unhealthy", Commentf("incorrect metrics name of 'upstream_peer_healthchecks_unhealthy' field"))
-	c.Assert(m.Value, Equals, int64(21), Commentf("incorrect value of metric 'upstream_peer_healthchecks_unhealthy'"))
+	c.Assert(m.Value, Equals, float64(21), Commentf("incorrect value of metric 'upstream_peer_healthchecks_unhealthy'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_downtime", Commentf("incorrect metrics name of 'upstream_peer_downtime' field"))
-	c.Assert(m.Value, Equals, int64(5432), Commentf("incorrect value of metric 'upstream_peer_downtime'"))
+	c.Assert(m.Value, Equals, float64(5432), Commentf("incorrect value of metric 'upstream_peer_downtime'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_downstart", Commentf("incorrect metrics name of 'upstream_peer_downstart' field"))
-	c.Assert(m.Value, Equals, int64(4321), Commentf("incorrect value of metric 'upstream_peer_downstart'"))
+	c.Assert(m.Value, Equals, float64(4321), Commentf("incorrect value of metric 'upstream_peer_downstart'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_selected", Commentf("incorrect metrics name of 'upstream_peer_selected' field"))
-	c.Assert(m.Value, Equals, int64(1451606400000), Commentf("incorrect value of metric 'upstream_peer_selected'"))
+	c.Assert(m.Value, Equals, float64(1451606400000), Commentf("incorrect value of metric 'upstream_peer_selected'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses", Commentf("incorrect metrics name of 'upstream_peer_responses' field"))
-	c.Assert(m.Value, Equals, int64(1111), Commentf("incorrect value of metric 'upstream_peer_responses'"))
+	c.Assert(m.Value, Equals, float64(1111), Commentf("incorrect value of metric 'upstream_peer_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(peerLabels, "1xx"), Commentf("incorrect set of labels")) // 1xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_1xx", Commentf("incorrect metrics name of 'upstream_peer_responses_1xx' field"))
-	c.Assert(m.Value, Equals, int64(1111), Commentf("incorrect value of metric 'upstream_peer_responses_1xx'"))
+	c.Assert(m.Value, Equals, float64(1111), Commentf("incorrect value of metric 'upstream_peer_responses_1xx'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses", Commentf("incorrect metrics name of 'upstream_peer_responses' field"))
-	c.Assert(m.Value, Equals, int64(2222), Commentf("incorrect value of metric 'upstream_peer_responses'"))
+	c.Assert(m.Value, Equals, float64(2222), Commentf("incorrect value of metric 'upstream_peer_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(peerLabels, "2xx"), Commentf("incorrect set of labels")) // 2xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_2xx", Commentf("incorrect metrics name of 'upstream_peer_responses_2xx' field"))
-	c.Assert(m.Value, Equals, int64(2222), Commentf("incorrect value of metric 'upstream_peer_responses_2xx'"))
+	c.Assert(m.Value, Equals, float64(2222), Commentf("incorrect value of metric 'upstream_peer_responses_2xx'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses", Commentf("incorrect metrics name of 'upstream_peer_responses' field"))
-	c.Assert(m.Value, Equals, int64(3333), Commentf("incorrect value of metric 'upstream_peer_responses'"))
+	c.Assert(m.Value, Equals, float64(3333), Commentf("incorrect value of metric 'upstream_peer_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(peerLabels, "3xx"), Commentf("incorrect set of labels")) // 3xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_3xx", Commentf("incorrect metrics name of 'upstream_peer_responses_3xx' field"))
-	c.Assert(m.Value, Equals, int64(3333), Commentf("incorrect value of metric 'upstream_peer_responses_3xx'"))
+	c.Assert(m.Value, Equals, float64(3333), Commentf("incorrect value of metric 'upstream_peer_responses_3xx'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses", Commentf("incorrect metrics name of 'upstream_peer_responses' field"))
-	c.Assert(m.Value, Equals, int64(4444), Commentf("incorrect value of metric 'upstream_peer_responses'"))
+	c.Assert(m.Value, Equals, float64(4444), Commentf("incorrect value of metric 'upstream_peer_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(peerLabels, "4xx"), Commentf("incorrect set of labels")) // 4xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_4xx", Commentf("incorrect metrics name of 'upstream_peer_responses_4xx' field"))
-	c.Assert(m.Value, Equals, int64(4444), Commentf("incorrect value of metric 'upstream_peer_responses_4xx'"))
+	c.Assert(m.Value, Equals, float64(4444), Commentf("incorrect value of metric 'upstream_peer_responses_4xx'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses", Commentf("incorrect metrics name of 'upstream_peer_responses' field"))
-	c.Assert(m.Value, Equals, int64(5555), Commentf("incorrect value of metric 'upstream_peer_responses'"))
+	c.Assert(m.Value, Equals, float64(5555), Commentf("incorrect value of metric 'upstream_peer_responses'"))
 	c.Assert(m.Labels, DeepEquals, codeLabels(peerLabels, "5xx"), Commentf("incorrect set of labels")) // 5xx
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_5xx", Commentf("incorrect metrics name of 'upstream_peer_responses_5xx' field"))
-	c.Assert(m.Value, Equals, int64(5555), Commentf("incorrect value of metric 'upstream_peer_responses_5xx'"))
+	c.Assert(m.Value, Equals, float64(5555), Commentf("incorrect value of metric 'upstream_peer_responses_5xx'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_responses_total", Commentf("incorrect metrics name of 'upstream_peer_responses_total' field"))
-	c.Assert(m.Value, Equals, int64(987654), Commentf("incorrect value of metric 'upstream_peer_responses_total'"))
+	c.Assert(m.Value, Equals, float64(987654), Commentf("incorrect value of metric 'upstream_peer_responses_total'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_healthchecks_last_passed", Commentf("incorrect metrics name of 'upstream_peer_healthchecks_last_passed' field"))
-	c.Assert(m.Value, Equals, false, Commentf("incorrect value of metric 'upstream_peer_healthchecks_last_passed'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'upstream_peer_healthchecks_last_passed'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_header_time", Commentf("incorrect metrics name of 'upstream_peer_header_time' field"))
-	c.Assert(m.Value, Equals, int64(2451606400000), Commentf("incorrect value of metric 'upstream_peer_header_time'"))
+	c.Assert(m.Value, Equals, float64(2451606400000), Commentf("incorrect value of metric 'upstream_peer_header_time'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_response_time", Commentf("incorrect metrics name of 'upstream_peer_response_time' field"))
-	c.Assert(m.Value, Equals, int64(3451606400000), Commentf("incorrect value of metric 'upstream_peer_response_time'"))
+	c.Assert(m.Value, Equals, float64(3451606400000), Commentf("incorrect value of metric 'upstream_peer_response_time'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "upstream_peer_max_conns", Commentf("incorrect metrics name of 'upstream_peer_max_conns' field"))
-	c.Assert(m.Value, Equals, 1000000, Commentf("incorrect value of metric 'upstream_peer_max_conns'"))
+	c.Assert(m.Value, Equals, float64(1000000), Commentf("incorrect value of metric 'upstream_peer_max_conns'"))
 	c.Assert(m.Labels, DeepEquals, peerLabels, Commentf("incorrect set of labels"))
 
 	cacheLabels := make(map[string]string)
@@ -545,117 +557,117 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_size", Commentf("incorrect metrics name of 'cache_size' field"))
-	c.Assert(m.Value, Equals, int64(12), Commentf("incorrect value of metric 'cache_size'"))
+	c.Assert(m.Value, Equals, float64(12), Commentf("incorrect value of metric 'cache_size'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_max_size", Commentf("incorrect metrics name of 'cache_max_size' field"))
-	c.Assert(m.Value, Equals, int64(12), Commentf("incorrect value of metric 'cache_max_size'"))
+	c.Assert(m.Value, Equals, float64(12), Commentf("incorrect value of metric 'cache_max_size'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_cold", Commentf("incorrect metrics name of 'cache_cold' field"))
-	c.Assert(m.Value, Equals, false, Commentf("incorrect value of metric 'cache_cold'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'cache_cold'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_hit_responses", Commentf("incorrect metrics name of 'cache_hit_responses' field"))
-	c.Assert(m.Value, Equals, int64(34), Commentf("incorrect value of metric 'cache_hit_responses'"))
+	c.Assert(m.Value, Equals, float64(34), Commentf("incorrect value of metric 'cache_hit_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_hit_bytes", Commentf("incorrect metrics name of 'cache_hit_bytes' field"))
-	c.Assert(m.Value, Equals, int64(45), Commentf("incorrect value of metric 'cache_hit_bytes'"))
+	c.Assert(m.Value, Equals, float64(45), Commentf("incorrect value of metric 'cache_hit_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_stale_responses", Commentf("incorrect metrics name of 'cache_stale_responses' field"))
-	c.Assert(m.Value, Equals, int64(56), Commentf("incorrect value of metric 'cache_stale_responses'"))
+	c.Assert(m.Value, Equals, float64(56), Commentf("incorrect value of metric 'cache_stale_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_stale_bytes", Commentf("incorrect metrics name of 'cache_stale_bytes' field"))
-	c.Assert(m.Value, Equals, int64(67), Commentf("incorrect value of metric 'cache_stale_bytes'"))
+	c.Assert(m.Value, Equals, float64(67), Commentf("incorrect value of metric 'cache_stale_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_updating_responses", Commentf("incorrect metrics name of 'cache_updating_responses' field"))
-	c.Assert(m.Value, Equals, int64(78), Commentf("incorrect value of metric 'cache_updating_responses'"))
+	c.Assert(m.Value, Equals, float64(78), Commentf("incorrect value of metric 'cache_updating_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_updating_bytes", Commentf("incorrect metrics name of 'cache_updating_bytes' field"))
-	c.Assert(m.Value, Equals, int64(89), Commentf("incorrect value of metric 'cache_updating_bytes'"))
+	c.Assert(m.Value, Equals, float64(89), Commentf("incorrect value of metric 'cache_updating_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_revalidated_responses", Commentf("incorrect metrics name of 'cache_revalidated_responses' field"))
-	c.Assert(m.Value, Equals, int64(90), Commentf("incorrect value of metric 'cache_revalidated_responses'"))
+	c.Assert(m.Value, Equals, float64(90), Commentf("incorrect value of metric 'cache_revalidated_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_revalidated_bytes", Commentf("incorrect metrics name of 'cache_revalidated_bytes' field"))
-	c.Assert(m.Value, Equals, int64(98), Commentf("incorrect value of metric 'cache_revalidated_bytes'"))
+	c.Assert(m.Value, Equals, float64(98), Commentf("incorrect value of metric 'cache_revalidated_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_miss_responses", Commentf("incorrect metrics name of 'cache_miss_responses' field"))
-	c.Assert(m.Value, Equals, int64(87), Commentf("incorrect value of metric 'cache_miss_responses'"))
+	c.Assert(m.Value, Equals, float64(87), Commentf("incorrect value of metric 'cache_miss_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_miss_bytes", Commentf("incorrect metrics name of 'cache_miss_bytes' field"))
-	c.Assert(m.Value, Equals, int64(76), Commentf("incorrect value of metric 'cache_miss_bytes'"))
+	c.Assert(m.Value, Equals, float64(76), Commentf("incorrect value of metric 'cache_miss_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_miss_responses_written", Commentf("incorrect metrics name of 'cache_miss_responses_written' field"))
-	c.Assert(m.Value, Equals, int64(65), Commentf("incorrect value of metric 'cache_miss_responses_written'"))
+	c.Assert(m.Value, Equals, float64(65), Commentf("incorrect value of metric 'cache_miss_responses_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_miss_bytes_written", Commentf("incorrect metrics name of 'cache_miss_bytes_written' field"))
-	c.Assert(m.Value, Equals, int64(76), Commentf("incorrect value of metric 'cache_miss_bytes_written'"))
+	c.Assert(m.Value, Equals, float64(76), Commentf("incorrect value of metric 'cache_miss_bytes_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_expired_responses", Commentf("incorrect metrics name of 'cache_expired_responses' field"))
-	c.Assert(m.Value, Equals, int64(43), Commentf("incorrect value of metric 'cache_expired_responses'"))
+	c.Assert(m.Value, Equals, float64(43), Commentf("incorrect value of metric 'cache_expired_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_expired_bytes", Commentf("incorrect metrics name of 'cache_expired_bytes' field"))
-	c.Assert(m.Value, Equals, int64(32), Commentf("incorrect value of metric 'cache_expired_bytes'"))
+	c.Assert(m.Value, Equals, float64(32), Commentf("incorrect value of metric 'cache_expired_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_expired_responses_written", Commentf("incorrect metrics name of 'cache_expired_responses_written' field"))
-	c.Assert(m.Value, Equals, int64(21), Commentf("incorrect value of metric 'cache_expired_responses_written'"))
+	c.Assert(m.Value, Equals, float64(21), Commentf("incorrect value of metric 'cache_expired_responses_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_expired_bytes_written", Commentf("incorrect metrics name of 'cache_expired_bytes_written' field"))
-	c.Assert(m.Value, Equals, int64(10), Commentf("incorrect value of metric 'cache_expired_bytes_written'"))
+	c.Assert(m.Value, Equals, float64(10), Commentf("incorrect value of metric 'cache_expired_bytes_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_responses", Commentf("incorrect metrics name of 'cache_responses' field"))
-	c.Assert(m.Value, Equals, int64(13), Commentf("incorrect value of metric 'cache_responses'"))
+	c.Assert(m.Value, Equals, float64(13), Commentf("incorrect value of metric 'cache_responses'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_bytes", Commentf("incorrect metrics name of 'cache_bytes' field"))
-	c.Assert(m.Value, Equals, int64(35), Commentf("incorrect value of metric 'cache_bytes'"))
+	c.Assert(m.Value, Equals, float64(35), Commentf("incorrect value of metric 'cache_bytes'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_responses_written", Commentf("incorrect metrics name of 'cache_responses_written' field"))
-	c.Assert(m.Value, Equals, int64(57), Commentf("incorrect value of metric 'cache_responses_written'"))
+	c.Assert(m.Value, Equals, float64(57), Commentf("incorrect value of metric 'cache_responses_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "cache_bytes_written", Commentf("incorrect metrics name of 'cache_bytes_written' field"))
-	c.Assert(m.Value, Equals, int64(79), Commentf("incorrect value of metric 'cache_bytes_written'"))
+	c.Assert(m.Value, Equals, float64(79), Commentf("incorrect value of metric 'cache_bytes_written'"))
 	c.Assert(m.Labels, DeepEquals, cacheLabels, Commentf("incorrect set of labels"))
 
 	streamLabels := make(map[string]string)
@@ -666,22 +678,22 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_zone_processing", Commentf("incorrect metrics name of 'stream_zone_processing' field"))
-	c.Assert(m.Value, Equals, 24, Commentf("incorrect value of metric 'stream_zone_processing'"))
+	c.Assert(m.Value, Equals, float64(24), Commentf("incorrect value of metric 'stream_zone_processing'"))
 	c.Assert(m.Labels, DeepEquals, streamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_zone_connections", Commentf("incorrect metrics name of 'stream_zone_connections' field"))
-	c.Assert(m.Value, Equals, 46, Commentf("incorrect value of metric 'stream_zone_connections'"))
+	c.Assert(m.Value, Equals, float64(46), Commentf("incorrect value of metric 'stream_zone_connections'"))
 	c.Assert(m.Labels, DeepEquals, streamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_zone_received", Commentf("incorrect metrics name of 'stream_zone_received' field"))
-	c.Assert(m.Value, Equals, int64(68), Commentf("incorrect value of metric 'stream_zone_received'"))
+	c.Assert(m.Value, Equals, float64(68), Commentf("incorrect value of metric 'stream_zone_received'"))
 	c.Assert(m.Labels, DeepEquals, streamLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_zone_sent", Commentf("incorrect metrics name of 'stream_zone_sent' field"))
-	c.Assert(m.Value, Equals, int64(80), Commentf("incorrect value of metric 'stream_zone_sent'"))
+	c.Assert(m.Value, Equals, float64(80), Commentf("incorrect value of metric 'stream_zone_sent'"))
 	c.Assert(m.Labels, DeepEquals, streamLabels, Commentf("incorrect set of labels"))
 
 	streamUpstreamLabels := make(map[string]string)
@@ -692,7 +704,7 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_zombies", Commentf("incorrect metrics name of 'stream_upstream_zombies' field"))
-	c.Assert(m.Value, Equals, 0, Commentf("incorrect value of metric 'stream_upstream_zombies'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_zombies'"))
 	c.Assert(m.Labels, DeepEquals, streamUpstreamLabels, Commentf("incorrect set of labels"))
 
 	streamPeerLabels := make(map[string]string)
@@ -703,97 +715,97 @@ func (s NginxPlusScraperSuite) TestScrape_Success(c *C) {
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_backup", Commentf("incorrect metrics name of 'stream_upstream_peer_backup' field"))
-	c.Assert(m.Value, Equals, false, Commentf("incorrect value of metric 'stream_upstream_peer_backup'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_backup'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_weight", Commentf("incorrect metrics name of 'stream_upstream_peer_weight' field"))
-	c.Assert(m.Value, Equals, 1, Commentf("incorrect value of metric 'stream_upstream_peer_weight'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'stream_upstream_peer_weight'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_state", Commentf("incorrect metrics name of 'stream_upstream_peer_state' field"))
-	c.Assert(m.Value, Equals, "up", Commentf("incorrect value of metric 'stream_upstream_peer_state'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'stream_upstream_peer_state'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_active", Commentf("incorrect metrics name of 'stream_upstream_peer_active' field"))
-	c.Assert(m.Value, Equals, 0, Commentf("incorrect value of metric 'stream_upstream_peer_active'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_active'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_connections", Commentf("incorrect metrics name of 'stream_upstream_peer_connections' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_connections'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_connections'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_sent", Commentf("incorrect metrics name of 'stream_upstream_peer_sent' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_sent'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_sent'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_received", Commentf("incorrect metrics name of 'stream_upstream_peer_received' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_received'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_received'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_fails", Commentf("incorrect metrics name of 'stream_upstream_peer_fails' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_fails'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_fails'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_unavail", Commentf("incorrect metrics name of 'stream_upstream_peer_unavail' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_unavail'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_unavail'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_checks", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_checks' field"))
-	c.Assert(m.Value, Equals, int64(40851), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_checks'"))
+	c.Assert(m.Value, Equals, float64(40851), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_checks'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_fails", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_fails' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_fails'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_fails'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_unhealthy", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_unhealthy' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_unhealthy'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_unhealthy'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_downtime", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_downtime' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_downtime'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_downtime'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_downstart", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_downstart' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_downstart'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_downstart'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_selected", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_selected' field"))
-	c.Assert(m.Value, Equals, int64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_selected'"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_selected'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_healthchecks_last_passed", Commentf("incorrect metrics name of 'stream_upstream_peer_healthchecks_last_passed' field"))
-	c.Assert(m.Value, Equals, true, Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_last_passed'"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect value of metric 'stream_upstream_peer_healthchecks_last_passed'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_connect_time", Commentf("incorrect metrics name of 'stream_upstream_peer_connect_time' field"))
-	c.Assert(m.Value, Equals, 993, Commentf("incorrect value of metric 'stream_upstream_peer_connect_time'"))
+	c.Assert(m.Value, Equals, float64(993), Commentf("incorrect value of metric 'stream_upstream_peer_connect_time'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_first_byte_time", Commentf("incorrect metrics name of 'stream_upstream_peer_first_byte_time' field"))
-	c.Assert(m.Value, Equals, 994, Commentf("incorrect value of metric 'stream_upstream_peer_first_byte_time'"))
+	c.Assert(m.Value, Equals, float64(994), Commentf("incorrect value of metric 'stream_upstream_peer_first_byte_time'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 
 	m = <-metrics
 	c.Assert(m.Name, Equals, "stream_upstream_peer_response_time", Commentf("incorrect metrics name of 'stream_upstream_peer_response_time' field"))
-	c.Assert(m.Value, Equals, 995, Commentf("incorrect value of metric 'stream_upstream_peer_response_time'"))
+	c.Assert(m.Value, Equals, float64(995), Commentf("incorrect value of metric 'stream_upstream_peer_response_time'"))
 	c.Assert(m.Labels, DeepEquals, streamPeerLabels, Commentf("incorrect set of labels"))
 }
 
@@ -808,3 +820,219 @@ func (s NginxPlusScraperSuite) TestScrape_Fail(c *C) {
 	c.Assert(err, NotNil, Commentf("error should be occurred"))
 	c.Assert(err.Error(), Equals, "error while decoding JSON response", Commentf("incorrect error massage of parsing json"))
 }
+
+func (s NginxPlusScraperSuite) TestScrapeUnknownFields_Success(c *C) {
+	scrape := func(stats string) []string {
+		metrics := make(chan metric.Metric, 1000)
+		err := scraper.NewNginxPlusScraper().Scrape(strings.NewReader(stats), metrics, map[string]string{"port": "8080"})
+		c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
+		close(metrics)
+
+		result := []string{}
+		for m := range metrics {
+			result = append(result, fmt.Sprintf("%s %v %v", m.Name, m.Labels, m.Value))
+		}
+		sort.Strings(result)
+
+		return result
+	}
+
+	extended := strings.Replace(
+		validNginxPlusStats,
+		"{",
+		`{"slabs": {"zone": {"pages": {"used": 1}, "slots": [1, 2]}}, "resolvers": [{"name": null}], "ssl_extra": true,`,
+		1,
+	)
+
+	c.Assert(scrape(extended), DeepEquals, scrape(validNginxPlusStats), Commentf("unknown fields changed scraped metrics"))
+}
+
+func (s NginxPlusScraperSuite) TestScrapeExcludedSections_Success(c *C) {
+	nginxPlusScraper := scraper.NewNginxPlusScraper(scraper.WithExcludedSections("upstreams", "caches", "stream"))
+
+	metrics := make(chan metric.Metric, 1000)
+	err := nginxPlusScraper.Scrape(strings.NewReader(validNginxPlusStats), metrics, map[string]string{"port": "8080"})
+	c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
+	close(metrics)
+
+	zones := 0
+	for m := range metrics {
+		for _, prefix := range []string{"upstream_", "cache_", "stream_"} {
+			c.Assert(strings.HasPrefix(m.Name, prefix), Equals, false, Commentf("metric '%s' of excluded section", m.Name))
+		}
+		if strings.HasPrefix(m.Name, "zone_") {
+			zones++
+		}
+	}
+
+	c.Assert(zones > 0, Equals, true, Commentf("missed metrics of server zones"))
+}
+
+func (s NginxPlusScraperSuite) TestScrapeResponsesModes_Success(c *C) {
+	stats := `{
+		"server_zones": {"zone": {"responses": {"1xx": 1, "2xx": 2, "3xx": 3, "4xx": 4, "5xx": 5, "total": 15}}},
+		"stream": {"server_zones": {"tcp": {"sessions": {"2xx": 20, "4xx": 40, "5xx": 50, "total": 110}}}}
+	}`
+
+	scrape := func(mode string) map[string]float64 {
+		metrics := make(chan metric.Metric, 1000)
+		err := scraper.NewNginxPlusScraper(scraper.WithResponses(mode)).Scrape(strings.NewReader(stats), metrics, map[string]string{})
+		c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
+		close(metrics)
+
+		result := map[string]float64{}
+		for m := range metrics {
+			if strings.Contains(m.Name, "responses") || strings.Contains(m.Name, "sessions") {
+				result[m.Name+"{"+m.Labels["code"]+"}"] = m.Value
+			}
+		}
+
+		return result
+	}
+
+	labelled := map[string]float64{
+		"zone_responses{1xx}":          1,
+		"zone_responses{2xx}":          2,
+		"zone_responses{3xx}":          3,
+		"zone_responses{4xx}":          4,
+		"zone_responses{5xx}":          5,
+		"zone_responses_total{}":       15,
+		"stream_zone_sessions{2xx}":    20,
+		"stream_zone_sessions{4xx}":    40,
+		"stream_zone_sessions{5xx}":    50,
+		"stream_zone_sessions_total{}": 110,
+	}
+	c.Assert(scrape(scraper.LabelledResponses), DeepEquals, labelled, Commentf("incorrect labelled responses"))
+
+	suffixed := map[string]float64{
+		"zone_responses_1xx{}":         1,
+		"zone_responses_2xx{}":         2,
+		"zone_responses_3xx{}":         3,
+		"zone_responses_4xx{}":         4,
+		"zone_responses_5xx{}":         5,
+		"zone_responses_total{}":       15,
+		"stream_zone_sessions_2xx{}":   20,
+		"stream_zone_sessions_4xx{}":   40,
+		"stream_zone_sessions_5xx{}":   50,
+		"stream_zone_sessions_total{}": 110,
+	}
+	c.Assert(scrape(scraper.SuffixedResponses), DeepEquals, suffixed, Commentf("incorrect suffixed responses"))
+
+	both := map[string]float64{}
+	for k, v := range labelled {
+		both[k] = v
+	}
+	for k, v := range suffixed {
+		both[k] = v
+	}
+	c.Assert(scrape(scraper.BothResponses), DeepEquals, both, Commentf("incorrect labelled and suffixed responses"))
+}
+
+func (s NginxPlusScraperSuite) TestScrapeOldVersion_Success(c *C) {
+	stats := `{
+		"version": 1,
+		"processes": {},
+		"connections": {"accepted": 1, "dropped": 0, "active": 1, "idle": 0},
+		"upstreams": {"backend": {"peers": [{"server": "127.0.0.1:80", "state": "up"}], "keepalive": 0}},
+		"caches": {"cache": {"size": 1, "hit": {"responses": 1, "bytes": 1}}}
+	}`
+
+	metrics := make(chan metric.Metric, 1000)
+	err := scraper.NewNginxPlusScraper().Scrape(strings.NewReader(stats), metrics, map[string]string{})
+	c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
+	close(metrics)
+
+	names := map[string]bool{}
+	for m := range metrics {
+		names[m.Name] = true
+	}
+
+	for _, name := range []string{"processes_respawned", "upstream_zombies", "upstream_peer_selected", "cache_revalidated_responses"} {
+		c.Assert(names[name], Equals, false, Commentf("metric '%s' of missing field is scraped", name))
+	}
+	for _, name := range []string{"connections_accepted", "upstream_peer_state", "cache_hit_responses"} {
+		c.Assert(names[name], Equals, true, Commentf("missed metric '%s'", name))
+	}
+}
+
+func (s NginxPlusScraperSuite) TestScrapePartial_Fail(c *C) {
+	stats := `{
+		"version": 8,
+		"connections": {"accepted": 1, "dropped": 0, "active": 1, "idle": 0},
+		"server_zones": {"bad": {"requests": "many"}, "good": {"requests": 10}},
+		"caches": [{"size": 1}],
+		"stream": {"server_zones": "none", "upstreams": {}}
+	}`
+
+	metrics := make(chan metric.Metric, 1000)
+	err := scraper.NewNginxPlusScraper().Scrape(strings.NewReader(stats), metrics, map[string]string{})
+	close(metrics)
+
+	partial, ok := err.(*scraper.PartialError)
+	c.Assert(ok, Equals, true, Commentf("unexpected error %v", err))
+	c.Assert(partial.Sections, DeepEquals, []string{"server_zones", "caches", "stream"}, Commentf("incorrect skipped sections"))
+
+	zones := map[string]bool{}
+	accepted := false
+	for m := range metrics {
+		if m.Name == "zone_requests" {
+			zones[m.Labels["zone"]] = true
+		}
+		accepted = accepted || m.Name == "connections_accepted"
+	}
+
+	c.Assert(zones, DeepEquals, map[string]bool{"good": true}, Commentf("incorrect scraped zones"))
+	c.Assert(accepted, Equals, true, Commentf("missed metrics of valid sections"))
+}
+
+func (s NginxPlusScraperSuite) TestScrapeStrict_Fail(c *C) {
+	stats := `{
+		"version": 4,
+		"slabs": {"zone": {"pages": {"used": 1}}},
+		"connections": {"accepted": 1, "dropped": 0, "active": 1, "idle": 0},
+		"server_zones": {"a": {"requests": 1, "ssl": {"handshakes": 1}}, "b": {"requests": 2, "ssl": {"handshakes": 2}}},
+		"upstreams": {"backend": {"peers": [{"server": "127.0.0.1:80", "state": "up", "selected": 1, "header_time": 5}], "keepalive": 0}},
+		"stream": {"server_zones": {"tcp": {"connections": 1, "foo": 1}}}
+	}`
+
+	metrics := make(chan metric.Metric, 1000)
+	err := scraper.NewNginxPlusScraper().Scrape(strings.NewReader(stats), metrics, map[string]string{})
+	c.Assert(err, IsNil, Commentf("unknown fields are reported without strict mode"))
+
+	metrics = make(chan metric.Metric, 1000)
+	err = scraper.NewNginxPlusScraper(scraper.WithStrict(true)).Scrape(strings.NewReader(stats), metrics, map[string]string{})
+	close(metrics)
+
+	partial, ok := err.(*scraper.PartialError)
+	c.Assert(ok, Equals, true, Commentf("unexpected error %v", err))
+	c.Assert(partial.Sections, HasLen, 0, Commentf("sections with unknown fields must not be skipped"))
+	c.Assert(partial.UnknownFields, DeepEquals, []string{
+		"server_zones.*.ssl",
+		"slabs",
+		"stream.server_zones.*.foo",
+		"upstreams.*.peers[].header_time",
+	}, Commentf("incorrect unknown fields"))
+
+	requests := 0
+	for m := range metrics {
+		if m.Name == "zone_requests" {
+			requests++
+		}
+	}
+	c.Assert(requests, Equals, 2, Commentf("missed metrics of zones with unknown fields"))
+}
+
+func BenchmarkNginxPlusScrape(b *testing.B) {
+	scr := scraper.NewNginxPlusScraper()
+	labels := map[string]string{"server": "localhost", "port": "80"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		metrics := make(chan metric.Metric, 1000)
+		if err := scr.Scrape(strings.NewReader(validNginxPlusStats), metrics, labels); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
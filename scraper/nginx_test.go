@@ -4,7 +4,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/monitoring-tools/prom-nginx-exporter/common"
 	"github.com/monitoring-tools/prom-nginx-exporter/metric"
 	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
 	. "gopkg.in/check.v1"
@@ -35,57 +34,43 @@ func (s NginxScraperSuite) TestScrape_Success(c *C) {
 	// assert number of active connections
 	m := <-metrics
 	c.Assert(m.Name, Equals, "active", Commentf("incorrect name of active connections"))
-	active, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'active' metric value to float64"))
-	c.Assert(active, Equals, float64(2), Commentf("incorrect number of active connections"))
+	c.Assert(m.Value, Equals, float64(2), Commentf("incorrect number of active connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of accept connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "accepts", Commentf("incorrect name of accepts connections"))
-	accepts, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'accepts' metric value to float64"))
-	c.Assert(accepts, Equals, float64(8522429), Commentf("incorrect number of accepts connections"))
+	c.Assert(m.Value, Equals, float64(8522429), Commentf("incorrect number of accepts connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of handled connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "handled", Commentf("incorrect name of handled connections"))
-	handled, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'handled' metric value to float64"))
-	c.Assert(handled, Equals, float64(8522429), Commentf("incorrect number of handled connections"))
+	c.Assert(m.Value, Equals, float64(8522429), Commentf("incorrect number of handled connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of requests connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "requests", Commentf("incorrect name of requests connections"))
-	requests, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'requests' metric value to float64"))
-	c.Assert(requests, Equals, float64(8641727), Commentf("incorrect number of requests connections"))
+	c.Assert(m.Value, Equals, float64(8641727), Commentf("incorrect number of requests connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of reading connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "reading", Commentf("incorrect name of reading connections"))
-	reading, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'reading' metric value to float64"))
-	c.Assert(reading, Equals, float64(0), Commentf("incorrect number of reading connections"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("incorrect number of reading connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of writing connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "writing", Commentf("incorrect name of writing connections"))
-	writing, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'writing' metric value to float64"))
-	c.Assert(writing, Equals, float64(1), Commentf("incorrect number of writing connections"))
+	c.Assert(m.Value, Equals, float64(1), Commentf("incorrect number of writing connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 
 	// assert number of waiting connections
 	m = <-metrics
 	c.Assert(m.Name, Equals, "waiting", Commentf("incorrect name of waiting connections"))
-	waiting, err := common.ConvertValueToFloat64(m.Value)
-	c.Assert(err, IsNil, Commentf("error occurred during convert 'waiting' metric value to float64"))
-	c.Assert(waiting, Equals, float64(3), Commentf("incorrect number of waiting connections"))
+	c.Assert(m.Value, Equals, float64(3), Commentf("incorrect number of waiting connections"))
 	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
 }
 
@@ -0,0 +1,300 @@
+package scraper
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+)
+
+const (
+	// officialNginxNamespace is the namespace of nginx metrics of nginx-prometheus-exporter
+	officialNginxNamespace = "nginx"
+	// officialNginxPlusNamespace is the namespace of nginx plus metrics of nginx-prometheus-exporter
+	officialNginxPlusNamespace = "nginxplus"
+)
+
+// Namespacer is implemented by scrapers exposing metrics in their own namespace instead of the namespace of exporter
+type Namespacer interface {
+	Namespace() string
+}
+
+// officialNginxNames are names of nginx-prometheus-exporter for metrics of nginx stats
+var officialNginxNames = map[string]string{
+	"active":   "connections_active",
+	"accepts":  "connections_accepted",
+	"handled":  "connections_handled",
+	"requests": "http_requests_total",
+	"reading":  "connections_reading",
+	"writing":  "connections_writing",
+	"waiting":  "connections_waiting",
+}
+
+// officialNginxPlusNames are names of nginx-prometheus-exporter for metrics of nginx plus stats, responses
+// and sessions are exposed labelled by status code only
+var officialNginxPlusNames = map[string]string{
+	"generation":     "generation",
+	"load_timestamp": "load_timestamp",
+
+	"processes_respawned": "processes_respawned",
+
+	"connections_accepted": "connections_accepted",
+	"connections_dropped":  "connections_dropped",
+	"connections_active":   "connections_active",
+	"connections_idle":     "connections_idle",
+
+	"ssl_handshakes":        "ssl_handshakes",
+	"ssl_handshakes_failed": "ssl_handshakes_failed",
+	"ssl_session_reuses":    "ssl_session_reuses",
+
+	"requests_total":   "http_requests_total",
+	"requests_current": "http_requests_current",
+
+	"zone_processing": "server_zone_processing",
+	"zone_requests":   "server_zone_requests",
+	"zone_responses":  "server_zone_responses",
+	"zone_discarded":  "server_zone_discarded",
+	"zone_received":   "server_zone_received",
+	"zone_sent":       "server_zone_sent",
+
+	"upstream_keepalive":       "upstream_keepalives",
+	"upstream_zombies":         "upstream_zombies",
+	"upstream_queue_size":      "upstream_queue_size",
+	"upstream_queue_max_size":  "upstream_queue_max_size",
+	"upstream_queue_overflows": "upstream_queue_overflows",
+
+	"upstream_peer_backup":                   "upstream_server_backup",
+	"upstream_peer_weight":                   "upstream_server_weight",
+	"upstream_peer_state":                    "upstream_server_state",
+	"upstream_peer_active":                   "upstream_server_active",
+	"upstream_peer_requests":                 "upstream_server_requests",
+	"upstream_peer_responses":                "upstream_server_responses",
+	"upstream_peer_sent":                     "upstream_server_sent",
+	"upstream_peer_received":                 "upstream_server_received",
+	"upstream_peer_fails":                    "upstream_server_fails",
+	"upstream_peer_unavail":                  "upstream_server_unavail",
+	"upstream_peer_healthchecks_checks":      "upstream_server_health_checks_checks",
+	"upstream_peer_healthchecks_fails":       "upstream_server_health_checks_fails",
+	"upstream_peer_healthchecks_unhealthy":   "upstream_server_health_checks_unhealthy",
+	"upstream_peer_healthchecks_last_passed": "upstream_server_health_checks_last_passed",
+	"upstream_peer_downtime":                 "upstream_server_downtime",
+	"upstream_peer_downstart":                "upstream_server_downstart",
+	"upstream_peer_selected":                 "upstream_server_selected",
+	"upstream_peer_header_time":              "upstream_server_header_time",
+	"upstream_peer_response_time":            "upstream_server_response_time",
+	"upstream_peer_max_conns":                "upstream_server_limit",
+
+	"cache_size":                      "cache_size",
+	"cache_max_size":                  "cache_max_size",
+	"cache_cold":                      "cache_cold",
+	"cache_hit_responses":             "cache_hit_responses",
+	"cache_hit_bytes":                 "cache_hit_bytes",
+	"cache_stale_responses":           "cache_stale_responses",
+	"cache_stale_bytes":               "cache_stale_bytes",
+	"cache_updating_responses":        "cache_updating_responses",
+	"cache_updating_bytes":            "cache_updating_bytes",
+	"cache_revalidated_responses":     "cache_revalidated_responses",
+	"cache_revalidated_bytes":         "cache_revalidated_bytes",
+	"cache_miss_responses":            "cache_miss_responses",
+	"cache_miss_bytes":                "cache_miss_bytes",
+	"cache_miss_responses_written":    "cache_miss_responses_written",
+	"cache_miss_bytes_written":        "cache_miss_bytes_written",
+	"cache_expired_responses":         "cache_expired_responses",
+	"cache_expired_bytes":             "cache_expired_bytes",
+	"cache_expired_responses_written": "cache_expired_responses_written",
+	"cache_expired_bytes_written":     "cache_expired_bytes_written",
+	"cache_responses":                 "cache_bypass_responses",
+	"cache_bytes":                     "cache_bypass_bytes",
+	"cache_responses_written":         "cache_bypass_responses_written",
+	"cache_bytes_written":             "cache_bypass_bytes_written",
+
+	"stream_zone_processing":  "stream_server_zone_processing",
+	"stream_zone_connections": "stream_server_zone_connections",
+	"stream_zone_received":    "stream_server_zone_received",
+	"stream_zone_sent":        "stream_server_zone_sent",
+	"stream_zone_sessions":    "stream_server_zone_sessions",
+
+	"stream_upstream_zombies": "stream_upstream_zombies",
+
+	"stream_upstream_peer_backup":                   "stream_upstream_server_backup",
+	"stream_upstream_peer_weight":                   "stream_upstream_server_weight",
+	"stream_upstream_peer_state":                    "stream_upstream_server_state",
+	"stream_upstream_peer_active":                   "stream_upstream_server_active",
+	"stream_upstream_peer_connections":              "stream_upstream_server_connections",
+	"stream_upstream_peer_sent":                     "stream_upstream_server_sent",
+	"stream_upstream_peer_received":                 "stream_upstream_server_received",
+	"stream_upstream_peer_fails":                    "stream_upstream_server_fails",
+	"stream_upstream_peer_unavail":                  "stream_upstream_server_unavail",
+	"stream_upstream_peer_healthchecks_checks":      "stream_upstream_server_health_checks_checks",
+	"stream_upstream_peer_healthchecks_fails":       "stream_upstream_server_health_checks_fails",
+	"stream_upstream_peer_healthchecks_unhealthy":   "stream_upstream_server_health_checks_unhealthy",
+	"stream_upstream_peer_healthchecks_last_passed": "stream_upstream_server_health_checks_last_passed",
+	"stream_upstream_peer_healthchecks_downtime":    "stream_upstream_server_downtime",
+	"stream_upstream_peer_healthchecks_downstart":   "stream_upstream_server_downstart",
+	"stream_upstream_peer_healthchecks_selected":    "stream_upstream_server_selected",
+	"stream_upstream_peer_connect_time":             "stream_upstream_server_connect_time",
+	"stream_upstream_peer_first_byte_time":          "stream_upstream_server_first_byte_time",
+	"stream_upstream_peer_response_time":            "stream_upstream_server_response_time",
+}
+
+// officialLabelNames are names of labels of nginx-prometheus-exporter for labels of metrics
+var officialLabelNames = map[string]string{
+	"zone":          "server_zone",
+	"serverAddress": "server",
+}
+
+// officialStates are values of states of upstream peers of nginx-prometheus-exporter
+var officialStates = map[string]float64{
+	"up":        1,
+	"draining":  2,
+	"down":      3,
+	"unavail":   4,
+	"checking":  5,
+	"unhealthy": 6,
+}
+
+// officialStateHelp is the help of states of upstream peers of nginx-prometheus-exporter
+const officialStateHelp = "Current state of the upstream peer: up = 1, draining = 2, down = 3, unavail = 4, checking = 5, unhealthy = 6."
+
+// OfficialScraper exposes metrics of nginx and nginx plus scrapers by names, labels and values
+// of nginx-prometheus-exporter
+type OfficialScraper struct {
+	scraper   Scraper
+	namespace string
+	names     map[string]string
+	families  []metric.Family
+}
+
+// NewOfficialScraper creates scraper exposing metrics of the scraper like nginx-prometheus-exporter,
+// scrapers other than nginx and nginx plus scrapers are returned as is
+func NewOfficialScraper(scr Scraper) Scraper {
+	switch s := scr.(type) {
+	case *NginxScraper:
+		return newOfficialScraper(s, officialNginxNamespace, officialNginxNames, nginxFamilies)
+	case *NginxPlusScraper:
+		plus := &NginxPlusScraper{excludedSections: s.excludedSections, responses: LabelledResponses, states: officialStates, strict: s.strict}
+		return newOfficialScraper(plus, officialNginxPlusNamespace, officialNginxPlusNames, nginxPlusFamilies)
+	default:
+		return scr
+	}
+}
+
+// newOfficialScraper creates scraper renaming metrics of the scraper by names, families of the scraper are renamed the same way
+func newOfficialScraper(scr Scraper, namespace string, names map[string]string, families []metric.Family) *OfficialScraper {
+	official := make([]metric.Family, 0, len(names))
+	for _, f := range families {
+		name, ok := names[f.Name]
+		if !ok {
+			continue
+		}
+
+		labels := make([]string, len(f.Labels))
+		for i, label := range f.Labels {
+			labels[i] = officialLabelName(label)
+		}
+
+		help := f.Help
+		if name == "upstream_server_state" || name == "stream_upstream_server_state" {
+			help = officialStateHelp
+		}
+
+		official = append(official, metric.Family{Name: name, Type: f.Type, Help: help, Labels: labels})
+	}
+
+	return &OfficialScraper{scraper: scr, namespace: namespace, names: names, families: official}
+}
+
+// Scrape scrapes stats by the wrapped scraper and renames its metrics, metrics without official names are dropped,
+// the panic of the wrapped scraper is returned as the error
+func (scr *OfficialScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	var (
+		scraped = make(chan metric.Metric, 100)
+		errs    = make(chan error, 1)
+	)
+
+	go func() {
+		defer close(scraped)
+		defer func() {
+			if r := recover(); r != nil {
+				errs <- fmt.Errorf("panic while scraping stats: %v", r)
+			}
+		}()
+
+		errs <- scr.scraper.Scrape(body, scraped, labels)
+	}()
+
+	for m := range scraped {
+		name, ok := scr.names[m.Name]
+		if !ok {
+			continue
+		}
+
+		m.Name = name
+		m.Labels = officialLabels(m.Labels)
+		metrics <- m
+	}
+
+	return <-errs
+}
+
+// Families returns the catalog of renamed metrics
+func (scr *OfficialScraper) Families() []metric.Family {
+	return scr.families
+}
+
+// Namespace returns the namespace of nginx-prometheus-exporter for metrics of the scraper
+func (scr *OfficialScraper) Namespace() string {
+	return scr.namespace
+}
+
+// ResetMetrics returns official names of metrics used by the wrapped scraper to detect resets of counters
+func (scr *OfficialScraper) ResetMetrics() []string {
+	detector, ok := scr.scraper.(ResetDetector)
+	if !ok {
+		return nil
+	}
+
+	names := []string{}
+	for _, name := range detector.ResetMetrics() {
+		names = append(names, scr.names[name])
+	}
+
+	return names
+}
+
+// Reset detects resets of counters by the wrapped scraper
+func (scr *OfficialScraper) Reset(previous, current []float64) bool {
+	return scr.scraper.(ResetDetector).Reset(previous, current)
+}
+
+// officialLabelName returns the name of label of nginx-prometheus-exporter
+func officialLabelName(name string) string {
+	if official, ok := officialLabelNames[name]; ok {
+		return official
+	}
+
+	return name
+}
+
+// officialLabels renames labels of the metric, labels are copied only when some of them are renamed
+// since the same labels are shared by metrics
+func officialLabels(labels map[string]string) map[string]string {
+	renamed := false
+	for name := range officialLabelNames {
+		if _, ok := labels[name]; ok {
+			renamed = true
+			break
+		}
+	}
+
+	if !renamed {
+		return labels
+	}
+
+	result := make(map[string]string, len(labels))
+	for name, value := range labels {
+		result[officialLabelName(name)] = value
+	}
+
+	return result
+}
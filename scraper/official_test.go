@@ -0,0 +1,71 @@
+package scraper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	. "gopkg.in/check.v1"
+)
+
+func TestOfficialScraper(t *testing.T) { TestingT(t) }
+
+type OfficialScraperSuite struct{}
+
+var _ = Suite(&OfficialScraperSuite{})
+
+func (s OfficialScraperSuite) TestScrape_Success(c *C) {
+	official := scraper.NewOfficialScraper(scraper.NewNginxPlusScraper(scraper.WithResponses(scraper.SuffixedResponses), scraper.WithExcludedSections("caches")))
+
+	namespacer, ok := official.(scraper.Namespacer)
+	c.Assert(ok, Equals, true, Commentf("official scraper has no namespace"))
+	c.Assert(namespacer.Namespace(), Equals, "nginxplus", Commentf("incorrect namespace"))
+
+	describer, ok := official.(scraper.Describer)
+	c.Assert(ok, Equals, true, Commentf("official scraper has no families"))
+
+	families := map[string]metric.Family{}
+	for _, f := range describer.Families() {
+		families[f.Name] = f
+	}
+
+	stats := strings.Replace(validNginxPlusStats, `"state": "up"`, `"state": "draining"`, -1)
+
+	metrics := make(chan metric.Metric, 1000)
+	err := official.Scrape(strings.NewReader(stats), metrics, map[string]string{"instance": "web-1", "port": "8080"})
+	c.Assert(err, IsNil, Commentf("error occurred during scrape nginx plus stats"))
+	close(metrics)
+
+	states, responses := 0, 0
+	for m := range metrics {
+		f, ok := families[m.Name]
+		c.Assert(ok, Equals, true, Commentf("undeclared metric '%s'", m.Name))
+		c.Assert(len(m.Labels), Equals, len(f.Labels)+2, Commentf("incorrect labels %v of metric '%s'", m.Labels, m.Name))
+		for _, name := range f.Labels {
+			_, ok := m.Labels[name]
+			c.Assert(ok, Equals, true, Commentf("missed label '%s' of metric '%s'", name, m.Name))
+		}
+
+		c.Assert(strings.HasPrefix(m.Name, "cache_"), Equals, false, Commentf("metric '%s' of excluded section", m.Name))
+
+		if m.Name == "upstream_server_state" || m.Name == "stream_upstream_server_state" {
+			c.Assert(m.Value, Equals, float64(2), Commentf("incorrect value of draining state"))
+			states++
+		}
+		if m.Name == "server_zone_responses" {
+			responses++
+		}
+	}
+
+	c.Assert(responses > 0, Equals, true, Commentf("missed responses labelled by status code"))
+	c.Assert(states > 0, Equals, true, Commentf("missed states of upstream peers"))
+}
+
+func (s OfficialScraperSuite) TestNewOfficialScraper_Success(c *C) {
+	healthcheck := scraper.NewHealthcheckScraper()
+	c.Assert(scraper.NewOfficialScraper(healthcheck), Equals, scraper.Scraper(healthcheck), Commentf("unsupported scraper is wrapped"))
+
+	_, ok := scraper.NewOfficialScraper(scraper.NewNginxScraper()).(*scraper.OfficialScraper)
+	c.Assert(ok, Equals, true, Commentf("nginx scraper isn't wrapped"))
+}
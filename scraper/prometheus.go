@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// exportedLabelPrefix is added to scraped labels conflicting with labels of the target
+const exportedLabelPrefix = "exported_"
+
+// PrometheusScraper is scraper of endpoints exposing metrics in Prometheus text format(e.g. nginx-lua-prometheus)
+type PrometheusScraper struct {
+	prefix string
+}
+
+// NewPrometheusScraper creates new Prometheus text format scraper, the prefix is added to names of scraped metrics
+func NewPrometheusScraper(prefix string) *PrometheusScraper {
+	return &PrometheusScraper{prefix: prefix}
+}
+
+// IsMetricNamePrefix checks whether names of metrics prefixed by the prefix are valid, the empty prefix is valid
+func IsMetricNamePrefix(prefix string) bool {
+	return prefix == "" || metricNameRegexp.MatchString(prefix)
+}
+
+// Scrape scrapes metrics in Prometheus text format, histograms and summaries are flattened to separate samples,
+// the sums, the counts and the buckets of them are counters
+func (scr *PrometheusScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(body)
+	if err != nil {
+		return fmt.Errorf("error while parsing Prometheus text format: %s", err)
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, m := range families[name].GetMetric() {
+			scr.scrapeMetric(scr.prefix+name, m, metrics, scr.sampleLabels(m, labels))
+		}
+	}
+
+	return nil
+}
+
+// scrapeMetric scrapes samples of the single metric of family
+func (scr *PrometheusScraper) scrapeMetric(name string, m *dto.Metric, metrics chan<- metric.Metric, labels map[string]string) {
+	switch {
+	case m.Counter != nil:
+		metrics <- metric.NewCounter(name, m.GetCounter().GetValue(), labels)
+	case m.Gauge != nil:
+		metrics <- metric.NewMetric(name, m.GetGauge().GetValue(), labels)
+	case m.Untyped != nil:
+		metrics <- metric.NewMetric(name, m.GetUntyped().GetValue(), labels)
+	case m.Summary != nil:
+		for _, q := range m.GetSummary().GetQuantile() {
+			quantileLabels := make(map[string]string)
+			for k, v := range labels {
+				quantileLabels[k] = v
+			}
+			quantileLabels["quantile"] = strconv.FormatFloat(q.GetQuantile(), 'g', -1, 64)
+			metrics <- metric.NewMetric(name, q.GetValue(), quantileLabels)
+		}
+		metrics <- metric.NewCounter(name+"_sum", m.GetSummary().GetSampleSum(), labels)
+		metrics <- metric.NewCounter(name+"_count", float64(m.GetSummary().GetSampleCount()), labels)
+	case m.Histogram != nil:
+		for _, b := range m.GetHistogram().GetBucket() {
+			bucketLabels := make(map[string]string)
+			for k, v := range labels {
+				bucketLabels[k] = v
+			}
+			bucketLabels["le"] = strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
+			metrics <- metric.NewCounter(name+"_bucket", float64(b.GetCumulativeCount()), bucketLabels)
+		}
+		metrics <- metric.NewCounter(name+"_sum", m.GetHistogram().GetSampleSum(), labels)
+		metrics <- metric.NewCounter(name+"_count", float64(m.GetHistogram().GetSampleCount()), labels)
+	}
+}
+
+// sampleLabels merges labels of the scraped metric with labels of the target,
+// the scraped labels conflicting with labels of the target are prefixed by "exported_"
+func (scr *PrometheusScraper) sampleLabels(m *dto.Metric, labels map[string]string) map[string]string {
+	sampleLabels := make(map[string]string)
+	for _, l := range m.GetLabel() {
+		name := l.GetName()
+		if _, ok := labels[name]; ok {
+			name = exportedLabelPrefix + name
+		}
+		sampleLabels[name] = l.GetValue()
+	}
+
+	for k, v := range labels {
+		sampleLabels[k] = v
+	}
+
+	return sampleLabels
+}
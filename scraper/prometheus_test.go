@@ -0,0 +1,103 @@
+package scraper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	. "gopkg.in/check.v1"
+)
+
+func TestPrometheusScraper(t *testing.T) { TestingT(t) }
+
+type PrometheusScraperSuite struct{}
+
+var _ = Suite(&PrometheusScraperSuite{})
+
+var validPrometheusStats = `# HELP nginx_http_connections Number of HTTP connections
+# TYPE nginx_http_connections gauge
+nginx_http_connections{state="active"} 12
+# HELP nginx_http_requests_total Number of HTTP requests
+# TYPE nginx_http_requests_total counter
+nginx_http_requests_total{host="example.com",server="backend",status="200"} 345
+# HELP nginx_http_request_duration_seconds HTTP request latency
+# TYPE nginx_http_request_duration_seconds histogram
+nginx_http_request_duration_seconds_bucket{le="0.5"} 3
+nginx_http_request_duration_seconds_bucket{le="+Inf"} 5
+nginx_http_request_duration_seconds_sum 1.5
+nginx_http_request_duration_seconds_count 5
+`
+
+func (s PrometheusScraperSuite) TestScrape_Success(c *C) {
+	prometheusScraper := scraper.NewPrometheusScraper("lua_")
+	reader := strings.NewReader(validPrometheusStats)
+
+	metrics := make(chan metric.Metric, 6)
+	labels := map[string]string{
+		"server": "localhost",
+		"port":   "9145",
+	}
+
+	err := prometheusScraper.Scrape(reader, metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape prometheus stats"))
+	c.Assert(len(metrics), Equals, 6, Commentf("incorrect number of metrics"))
+
+	m := <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_connections", Commentf("incorrect metrics name of 'nginx_http_connections' family"))
+	c.Assert(m.Value, Equals, float64(12), Commentf("incorrect value of metric 'nginx_http_connections'"))
+	c.Assert(m.Labels, DeepEquals, map[string]string{"server": "localhost", "port": "9145", "state": "active"}, Commentf("incorrect set of labels"))
+
+	bucketLabels := map[string]string{"server": "localhost", "port": "9145"}
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_request_duration_seconds_bucket", Commentf("incorrect metrics name of histogram bucket"))
+	c.Assert(m.Value, Equals, float64(3), Commentf("incorrect value of histogram bucket"))
+	c.Assert(m.Type, Equals, metric.Counter, Commentf("histogram bucket should be counter"))
+	c.Assert(m.Labels, DeepEquals, map[string]string{"server": "localhost", "port": "9145", "le": "0.5"}, Commentf("incorrect set of labels"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_request_duration_seconds_bucket", Commentf("incorrect metrics name of histogram bucket"))
+	c.Assert(m.Value, Equals, float64(5), Commentf("incorrect value of histogram bucket"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_request_duration_seconds_sum", Commentf("incorrect metrics name of histogram sum"))
+	c.Assert(m.Value, Equals, float64(1.5), Commentf("incorrect value of histogram sum"))
+	c.Assert(m.Type, Equals, metric.Counter, Commentf("histogram sum should be counter"))
+	c.Assert(m.Labels, DeepEquals, bucketLabels, Commentf("incorrect set of labels"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_request_duration_seconds_count", Commentf("incorrect metrics name of histogram count"))
+	c.Assert(m.Value, Equals, float64(5), Commentf("incorrect value of histogram count"))
+	c.Assert(m.Type, Equals, metric.Counter, Commentf("histogram count should be counter"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "lua_nginx_http_requests_total", Commentf("incorrect metrics name of 'nginx_http_requests_total' family"))
+	c.Assert(m.Value, Equals, float64(345), Commentf("incorrect value of metric 'nginx_http_requests_total'"))
+	c.Assert(m.Labels, DeepEquals, map[string]string{
+		"server":          "localhost",
+		"port":            "9145",
+		"host":            "example.com",
+		"exported_server": "backend",
+		"status":          "200",
+	}, Commentf("conflicting labels should be prefixed by 'exported_'"))
+}
+
+func (s PrometheusScraperSuite) TestScrape_Fail(c *C) {
+	prometheusScraper := scraper.NewPrometheusScraper("")
+	metrics := make(chan metric.Metric, 1)
+
+	err := prometheusScraper.Scrape(strings.NewReader("nginx_http_connections{state=\"active\" 12\n"), metrics, map[string]string{})
+	c.Assert(err, NotNil, Commentf("should be error of parsing prometheus text format"))
+	c.Assert(strings.HasPrefix(err.Error(), "error while parsing Prometheus text format"), Equals, true, Commentf("incorrect error message"))
+}
+
+func (s PrometheusScraperSuite) TestIsMetricNamePrefix(c *C) {
+	for _, prefix := range []string{"", "lua_", "my_app:", "_"} {
+		c.Assert(scraper.IsMetricNamePrefix(prefix), Equals, true, Commentf("prefix '%s' should be valid", prefix))
+	}
+
+	for _, prefix := range []string{"my-app", "1app_", "app "} {
+		c.Assert(scraper.IsMetricNamePrefix(prefix), Equals, false, Commentf("prefix '%s' should be invalid", prefix))
+	}
+}
@@ -0,0 +1,196 @@
+package scraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+)
+
+const (
+	// regexValueGroup is the default template of metric value
+	regexValueGroup = "${value}"
+)
+
+var (
+	// invalidMetricNameChars matches characters which are not allowed in metric name
+	invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	// metricNameRegexp matches valid names of metrics
+	metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	// labelNameRegexp matches valid names of labels
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// templateGroupRegexp matches references to capture groups in templates(e.g. ${name} or $1)
+	templateGroupRegexp = regexp.MustCompile(`\$(\{\w+\}|\w+)`)
+)
+
+// RegexMapping is the mapping of lines of text stats to metrics
+type RegexMapping struct {
+	Rules []*RegexRule `yaml:"rules"`
+}
+
+// RegexRule describes metrics taken from lines of text stats matched by the regular expression, example:
+//
+//	regex: '(?P<state>Reading|Writing|Waiting): (?P<value>\d+)'
+//	name: connections
+//	type: gauge
+//	labels:
+//	  state: ${state}
+//
+// The name, the value and labels are templates expanded by named(${name}) or numbered(${1}) capture groups,
+// the value is taken from the group "value" by default. Every match of the line produces the metric.
+type RegexRule struct {
+	Regex        string             `yaml:"regex"`
+	Name         string             `yaml:"name"`
+	Value        string             `yaml:"value"`
+	Type         string             `yaml:"type"`
+	Labels       map[string]string  `yaml:"labels"`
+	ValueMapping map[string]float64 `yaml:"value_mapping"`
+
+	regex *regexp.Regexp
+}
+
+// LoadRegexMapping loads the mapping of text stats to metrics from YAML file
+func LoadRegexMapping(filename string) (*RegexMapping, error) {
+	mapping := &RegexMapping{}
+	if err := loadMappingFile(filename, mapping); err != nil {
+		return nil, fmt.Errorf("error loading regex mapping: %s", err)
+	}
+
+	return mapping, nil
+}
+
+// compile validates the mapping and compiles regular expressions of rules
+func (m *RegexMapping) compile() error {
+	if len(m.Rules) == 0 {
+		return fmt.Errorf("no rules defined")
+	}
+
+	for _, rule := range m.Rules {
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rule '%s': %s", rule.Regex, err)
+		}
+	}
+
+	return nil
+}
+
+// compile validates the rule and compiles its regular expression
+func (r *RegexRule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("empty name")
+	}
+
+	// groups are expanded at scrape time, so only the literal part of the name is checked
+	if !metricNameRegexp.MatchString(templateGroupRegexp.ReplaceAllString(r.Name, "x")) {
+		return fmt.Errorf("invalid name '%s'", r.Name)
+	}
+
+	for name := range r.Labels {
+		if err := checkMappingLabelName(name); err != nil {
+			return err
+		}
+	}
+
+	switch r.Type {
+	case "":
+		r.Type = "gauge"
+	case "gauge", "counter":
+	default:
+		return fmt.Errorf("unsupported type '%s'", r.Type)
+	}
+
+	if r.Value == "" {
+		r.Value = regexValueGroup
+	}
+
+	var err error
+	if r.regex, err = regexp.Compile(r.Regex); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RegexScraper is scraper of line-oriented text stats driven by regular expressions
+type RegexScraper struct {
+	mapping *RegexMapping
+}
+
+// NewRegexScraper creates new text stats scraper using the mapping
+func NewRegexScraper(mapping *RegexMapping) *RegexScraper {
+	return &RegexScraper{mapping: mapping}
+}
+
+// Scrape scrapes metrics from every line matched by rules of the mapping,
+// the values which can't be converted are skipped and the first conversion error is returned
+func (scr *RegexScraper) Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error {
+	var (
+		scanner  = bufio.NewScanner(body)
+		firstErr error
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		for _, rule := range scr.mapping.Rules {
+			for _, match := range rule.regex.FindAllStringSubmatchIndex(line, -1) {
+				if err := scr.scrapeMatch(rule, line, match, metrics, labels); err != nil && firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return firstErr
+}
+
+// scrapeMatch scrapes metric from the single match of the rule
+func (scr *RegexScraper) scrapeMatch(
+	rule *RegexRule,
+	line string,
+	match []int,
+	metrics chan<- metric.Metric,
+	labels map[string]string,
+) error {
+	expand := func(template string) string {
+		return string(rule.regex.ExpandString(nil, template, line, match))
+	}
+
+	name := invalidMetricNameChars.ReplaceAllString(strings.ToLower(expand(rule.Name)), "_")
+	if !metricNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid metric name '%s' of rule '%s' for line '%s'", name, rule.Regex, line)
+	}
+
+	raw := expand(rule.Value)
+	value, ok := rule.ValueMapping[raw]
+	if !ok {
+		var err error
+		if value, err = strconv.ParseFloat(raw, 64); err != nil {
+			return fmt.Errorf("unable to convert value of metric '%s': %s", name, err)
+		}
+	}
+
+	metricLabels := make(map[string]string, len(labels)+len(rule.Labels))
+	for k, v := range labels {
+		metricLabels[k] = v
+	}
+	for k, template := range rule.Labels {
+		setMappingLabel(metricLabels, labels, k, expand(template))
+	}
+
+	if rule.Type == "counter" {
+		metrics <- metric.NewCounter(name, value, metricLabels)
+	} else {
+		metrics <- metric.NewMetric(name, value, metricLabels)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package scraper_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"github.com/monitoring-tools/prom-nginx-exporter/scraper"
+	. "gopkg.in/check.v1"
+)
+
+func TestRegexScraper(t *testing.T) { TestingT(t) }
+
+type RegexScraperSuite struct{}
+
+var _ = Suite(&RegexScraperSuite{})
+
+var validRegexMapping = `
+rules:
+  - regex: '^Active connections:\s+(?P<value>\d+)'
+    name: active
+  - regex: '(?P<state>Reading|Writing|Waiting): (?P<value>\d+)'
+    name: connections
+    labels:
+      state: ${state}
+  - regex: '^(?P<name>Max pool size) = (?P<count>\d+)'
+    name: ${name}
+    value: ${count}
+    type: counter
+  - regex: '^App (?P<app>\S+) is (?P<status>up|down)'
+    name: app_status
+    value: ${status}
+    labels:
+      app: ${app}
+    value_mapping:
+      up: 1
+      down: 0
+`
+
+var validRegexStats = "Active connections: 2\n" +
+	"Reading: 0 Writing: 1 Waiting: 3\n" +
+	"Max pool size = 6\n" +
+	"App /var/www/site is down\n"
+
+// loadRegexMapping writes mapping to the temporary file and loads it
+func loadRegexMapping(c *C, content string) (*scraper.RegexMapping, error) {
+	filename := filepath.Join(c.MkDir(), "mapping.yml")
+	c.Assert(ioutil.WriteFile(filename, []byte(content), 0644), IsNil, Commentf("unable to write mapping file"))
+
+	return scraper.LoadRegexMapping(filename)
+}
+
+func (s RegexScraperSuite) TestScrape_Success(c *C) {
+	mapping, err := loadRegexMapping(c, validRegexMapping)
+	c.Assert(err, IsNil, Commentf("error occurred during load of regex mapping"))
+
+	regexScraper := scraper.NewRegexScraper(mapping)
+	metrics := make(chan metric.Metric, 6)
+	labels := map[string]string{"server": "localhost", "port": "8080"}
+
+	err = regexScraper.Scrape(strings.NewReader(validRegexStats), metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape text stats"))
+	c.Assert(len(metrics), Equals, 6, Commentf("incorrect number of metrics"))
+
+	m := <-metrics
+	c.Assert(m.Name, Equals, "active", Commentf("incorrect metrics name of active connections"))
+	c.Assert(m.Value, Equals, float64(2), Commentf("incorrect number of active connections"))
+	c.Assert(m.Labels, DeepEquals, labels, Commentf("incorrect set of labels"))
+
+	for _, state := range []struct {
+		name  string
+		value float64
+	}{{"Reading", 0}, {"Writing", 1}, {"Waiting", 3}} {
+		m = <-metrics
+		c.Assert(m.Name, Equals, "connections", Commentf("incorrect metrics name of %s connections", state.name))
+		c.Assert(m.Value, Equals, state.value, Commentf("incorrect number of %s connections", state.name))
+		c.Assert(m.Labels, DeepEquals, map[string]string{"server": "localhost", "port": "8080", "state": state.name}, Commentf("incorrect set of labels"))
+	}
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "max_pool_size", Commentf("metric name should be taken from capture group and sanitized"))
+	c.Assert(m.Type, Equals, metric.Counter, Commentf("incorrect type of metric 'max_pool_size'"))
+	c.Assert(m.Value, Equals, float64(6), Commentf("incorrect value of metric 'max_pool_size'"))
+
+	m = <-metrics
+	c.Assert(m.Name, Equals, "app_status", Commentf("incorrect metrics name of app status"))
+	c.Assert(m.Value, Equals, float64(0), Commentf("value should be converted using value mapping"))
+	c.Assert(m.Labels, DeepEquals, map[string]string{"server": "localhost", "port": "8080", "app": "/var/www/site"}, Commentf("incorrect set of labels"))
+}
+
+func (s RegexScraperSuite) TestScrape_Fail(c *C) {
+	mapping, err := loadRegexMapping(c, "rules:\n  - regex: '^Requests: (?P<value>\\S+)'\n    name: requests\n")
+	c.Assert(err, IsNil, Commentf("error occurred during load of regex mapping"))
+
+	regexScraper := scraper.NewRegexScraper(mapping)
+	metrics := make(chan metric.Metric, 1)
+
+	err = regexScraper.Scrape(strings.NewReader("Requests: many\n"), metrics, map[string]string{})
+	c.Assert(err, NotNil, Commentf("should be error of parsing value"))
+	c.Assert(err.Error(), Equals, "unable to convert value of metric 'requests': strconv.ParseFloat: parsing \"many\": invalid syntax", Commentf("incorrect error message"))
+}
+
+func (s RegexScraperSuite) TestLoadMapping_Fail(c *C) {
+	_, err := loadRegexMapping(c, "rules:\n  - regex: '(?P<value>\\d+'\n    name: requests\n")
+	c.Assert(err, NotNil, Commentf("should be error of compiling regular expression"))
+	c.Assert(strings.Contains(err.Error(), "missing closing )"), Equals, true, Commentf("incorrect error message"))
+
+	_, err = loadRegexMapping(c, "rules:\n  - regex: '(?P<value>\\d+)'\n")
+	c.Assert(err, NotNil, Commentf("should be error of empty name"))
+	c.Assert(strings.HasSuffix(err.Error(), "rule '(?P<value>\\d+)': empty name"), Equals, true, Commentf("incorrect error message"))
+
+	for _, rule := range []string{
+		"name: 2xx_requests",
+		"name: requests-total",
+		"name: '${1}-requests'",
+		"name: requests\n    labels:\n      1state: x",
+		"name: requests\n    labels:\n      server-name: x",
+		"name: requests\n    labels:\n      server: x",
+		"name: requests\n    labels:\n      port: x",
+		"name: requests\n    labels:\n      instance: x",
+	} {
+		_, err = loadRegexMapping(c, "rules:\n  - regex: '(\\w+) (?P<value>\\d+)'\n    "+rule+"\n")
+		c.Assert(err, NotNil, Commentf("invalid rule '%s' is accepted", rule))
+	}
+
+	_, err = loadRegexMapping(c, "rules:\n  - regex: '(\\w+) (?P<value>\\d+)'\n    name: '${1}_requests'\n    labels:\n      state: ${1}\n")
+	c.Assert(err, IsNil, Commentf("valid templates of rule are rejected"))
+
+	_, err = loadRegexMapping(c, "rules:\n  - regex: '(\\w+) (?P<value>\\d+)'\n    name: requests\n    labels:\n      server: ${1}\n")
+	c.Assert(err, NotNil, Commentf("should be error of reserved label name"))
+	c.Assert(strings.HasSuffix(err.Error(), "reserved label name 'server'"), Equals, true, Commentf("incorrect error message"))
+}
+
+func (s RegexScraperSuite) TestScrapeStaticLabels_Success(c *C) {
+	mapping, err := loadRegexMapping(c, "rules:\n  - regex: '(\\w+): (?P<value>\\d+)'\n    name: connections\n    labels:\n      state: ${1}\n")
+	c.Assert(err, IsNil, Commentf("error occurred during load of regex mapping"))
+
+	regexScraper := scraper.NewRegexScraper(mapping)
+	metrics := make(chan metric.Metric, 1)
+	labels := map[string]string{"server": "localhost", "port": "8080", "state": "static"}
+
+	err = regexScraper.Scrape(strings.NewReader("Reading: 2\n"), metrics, labels)
+	c.Assert(err, IsNil, Commentf("error occurred during scrape text stats"))
+	c.Assert(len(metrics), Equals, 1, Commentf("incorrect number of metrics"))
+
+	m := <-metrics
+	c.Assert(m.Labels, DeepEquals, map[string]string{
+		"server":         "localhost",
+		"port":           "8080",
+		"state":          "static",
+		"exported_state": "Reading",
+	}, Commentf("labels of the target should not be overwritten"))
+}
@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/monitoring-tools/prom-nginx-exporter/metric"
+	"gopkg.in/yaml.v2"
+)
+
+// Scraper is the interface of scraper parsing stats of nginx module
+type Scraper interface {
+	// Scrape parses stats from body and sends them to the metrics channel with passed labels
+	Scrape(body io.Reader, metrics chan<- metric.Metric, labels map[string]string) error
+}
+
+// reservedMappingLabelNames are names of labels which can't be set by mapping files: labels identifying the target
+// and labels of metrics of the state of scraping
+var reservedMappingLabelNames = map[string]bool{"server": true, "instance": true, "port": true, "reason": true, "section": true}
+
+// errUnexpectedValue describes the value of stats of unexpected type skipped while decoding
+var errUnexpectedValue = errors.New("unexpected type of value")
+
+// PartialError is the error of scraping stats whose sections were skipped due to values of unexpected types
+// or which have fields unknown to the scraper, metrics of other sections are scraped successfully
+type PartialError struct {
+	// Sections are names of skipped sections, the section is repeated for every skipped value
+	Sections []string
+	// UnknownFields are sorted JSON paths of fields which are not exported, reported only in strict mode
+	UnknownFields []string
+}
+
+// Error returns message of the error
+func (e *PartialError) Error() string {
+	messages := []string{}
+	if len(e.Sections) > 0 {
+		messages = append(messages, fmt.Sprintf("skipped sections of stats with values of unexpected types: %s", strings.Join(e.Sections, ", ")))
+	}
+	if len(e.UnknownFields) > 0 {
+		messages = append(messages, fmt.Sprintf("unknown fields of stats: %s", strings.Join(e.UnknownFields, ", ")))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// skip records the section skipped due to the value of unexpected type, other errors are returned as is
+func (e *PartialError) skip(section string, err error) error {
+	if _, ok := err.(*json.UnmarshalTypeError); ok || err == errUnexpectedValue {
+		e.Sections = append(e.Sections, section)
+		return nil
+	}
+
+	return err
+}
+
+// mapping is the interface of mapping of stats to metrics loaded from the file
+type mapping interface {
+	// compile validates the mapping and prepares it for scraping
+	compile() error
+}
+
+// loadMappingFile loads the mapping from YAML file
+func loadMappingFile(filename string, m mapping) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %s", filename, err)
+	}
+
+	if err := yaml.UnmarshalStrict(content, m); err != nil {
+		return fmt.Errorf("error parsing file '%s': %s", filename, err)
+	}
+
+	if err := m.compile(); err != nil {
+		return fmt.Errorf("invalid file '%s': %s", filename, err)
+	}
+
+	return nil
+}
+
+// withLabel returns the copy of labels with the added label, the copy is made once per zone, upstream or peer
+// and shared by all its metrics, since labels of sent metrics must not be changed
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+
+	return result
+}
+
+// checkMappingLabelName checks that the label name of mapping is valid and isn't reserved for labels of the target
+func checkMappingLabelName(name string) error {
+	if !labelNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid label name '%s'", name)
+	}
+
+	if reservedMappingLabelNames[name] {
+		return fmt.Errorf("reserved label name '%s'", name)
+	}
+
+	return nil
+}
+
+// setMappingLabel sets the label produced by mapping, the label conflicting with labels of the target(e.g. static
+// labels) is prefixed by "exported_", so labels of the target are never overwritten
+func setMappingLabel(metricLabels map[string]string, labels map[string]string, name, value string) {
+	if _, ok := labels[name]; ok {
+		name = exportedLabelPrefix + name
+	}
+	metricLabels[name] = value
+}
+
+// boolValue converts bool value of stats to metric value, true is 1 and false is 0
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// stateValue converts state of upstream peer to metric value, "up" is 1 and other states are 0
+func stateValue(state string) float64 {
+	if strings.ToLower(state) == "up" {
+		return 1
+	}
+
+	return 0
+}